@@ -0,0 +1,233 @@
+package pebbleclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// ErrBatchAborted is the error assigned to batch items that were never run
+// because an earlier item failed in fail-fast mode.
+var ErrBatchAborted = errors.New("Batch aborted")
+
+// BatchOptions contains options for a batch.
+type BatchOptions struct {
+	// Concurrency is the maximum number of requests executed simultaneously.
+	// Defaults to 10.
+	Concurrency int
+
+	// FailFast stops the batch at the first error. Requests that have not yet
+	// been started are not run, and requests in flight are cancelled. If false,
+	// every request is run and all errors are collected.
+	FailFast bool
+}
+
+// BatchItem is a single queued request in a batch.
+type BatchItem struct {
+	Path    string
+	Options *RequestOptions
+	Method  string
+	Body    []byte
+
+	// Result is where the decoded response is stored, unless nil.
+	Result interface{}
+
+	// Err is the error for this item, or nil if it succeeded. Only valid after
+	// the batch has been executed.
+	Err error
+}
+
+// BatchError is returned by Batch.Execute when one or more items failed in
+// collect-all mode.
+type BatchError struct {
+	// Errors contains one entry per item, in input order. Entries for items
+	// that succeeded are nil.
+	Errors []error
+}
+
+func (err *BatchError) Error() string {
+	var count int
+	var first error
+	for _, e := range err.Errors {
+		if e != nil {
+			if first == nil {
+				first = e
+			}
+			count++
+		}
+	}
+	return fmt.Sprintf("%d of %d batch requests failed; first error: %s",
+		count, len(err.Errors), first)
+}
+
+// Batch queues requests to a client and executes them with bounded parallelism.
+// A batch is not safe for concurrent use, and can only be executed once.
+type Batch struct {
+	client Client
+	opts   BatchOptions
+	items  []*BatchItem
+}
+
+// NewBatch constructs a new batch which executes requests with the given client.
+func NewBatch(client Client, opts BatchOptions) *Batch {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	return &Batch{
+		client: client,
+		opts:   opts,
+	}
+}
+
+// Get queues a GET request. See Client.Get.
+func (batch *Batch) Get(path string, opts *RequestOptions, result interface{}) *BatchItem {
+	return batch.Add(path, opts, "GET", nil, result)
+}
+
+// Head queues a HEAD request. See Client.Head.
+func (batch *Batch) Head(path string, opts *RequestOptions) *BatchItem {
+	return batch.Add(path, opts, "HEAD", nil, nil)
+}
+
+// Post queues a POST request. See Client.Post.
+func (batch *Batch) Post(path string, opts *RequestOptions, body []byte, result interface{}) *BatchItem {
+	return batch.Add(path, opts, "POST", body, result)
+}
+
+// Put queues a PUT request. See Client.Put.
+func (batch *Batch) Put(path string, opts *RequestOptions, body []byte, result interface{}) *BatchItem {
+	return batch.Add(path, opts, "PUT", body, result)
+}
+
+// Delete queues a DELETE request. See Client.Delete.
+func (batch *Batch) Delete(path string, opts *RequestOptions, result interface{}) *BatchItem {
+	return batch.Add(path, opts, "DELETE", nil, result)
+}
+
+// Add queues a request. Bodies are passed as byte slices rather than readers
+// so that the batch does not need to worry about who owns the reader.
+func (batch *Batch) Add(
+	path string,
+	opts *RequestOptions,
+	method string,
+	body []byte,
+	result interface{}) *BatchItem {
+	item := &BatchItem{
+		Path:    path,
+		Options: opts,
+		Method:  method,
+		Body:    body,
+		Result:  result,
+	}
+	batch.items = append(batch.items, item)
+	return item
+}
+
+// Items returns the queued items, in input order.
+func (batch *Batch) Items() []*BatchItem {
+	return batch.items
+}
+
+// Len returns the number of queued items.
+func (batch *Batch) Len() int {
+	return len(batch.items)
+}
+
+// Execute runs all queued requests, and waits for them to complete. Each item's
+// Err field is set to its outcome.
+//
+// In fail-fast mode, the first error encountered is returned, and items that
+// were not run get ErrBatchAborted. Otherwise, a *BatchError is returned if any
+// item failed.
+func (batch *Batch) Execute() error {
+	if len(batch.items) == 0 {
+		return nil
+	}
+
+	parent := batch.client.GetOptions().Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	client := batch.client.WithOptions(Options{Ctx: ctx})
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	indexes := make(chan int)
+	workers := batch.opts.Concurrency
+	if workers > len(batch.items) {
+		workers = len(batch.items)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := batch.items[i]
+				if ctx.Err() != nil {
+					item.Err = ErrBatchAborted
+					continue
+				}
+				var body io.Reader
+				if item.Body != nil {
+					body = bytes.NewReader(item.Body)
+				}
+				item.Err = client.Do(item.Path, item.Options, item.Method, body, item.Result)
+				if item.Err != nil && batch.opts.FailFast {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = item.Err
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(batch.items) && ctx.Err() == nil; next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for ; next < len(batch.items); next++ {
+		batch.items[next].Err = ErrBatchAborted
+	}
+
+	if batch.opts.FailFast {
+		if firstErr == nil && parent.Err() != nil {
+			return parent.Err()
+		}
+		return firstErr
+	}
+
+	errs := make([]error, len(batch.items))
+	var failed bool
+	for i, item := range batch.items {
+		errs[i] = item.Err
+		if item.Err != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return &BatchError{Errors: errs}
+	}
+	return nil
+}
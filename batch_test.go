@@ -0,0 +1,148 @@
+package pebbleclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestBatch_Execute_resultsInInputOrder(t *testing.T) {
+	client, server, err := newClientAndServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := strings.TrimPrefix(req.URL.Path, "/api/frobnitz/v1/hello/")
+		if name == "a" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		writeJSONDatum(w, http.StatusOK, &Datum{Message: name})
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	batch := pebbleclient.NewBatch(client, pebbleclient.BatchOptions{Concurrency: 3})
+	names := []string{"a", "b", "c", "d", "e"}
+	results := make([]Datum, len(names))
+	for i, name := range names {
+		batch.Get("/hello/:name", &pebbleclient.RequestOptions{
+			Params: pebbleclient.Params{"name": name},
+		}, &results[i])
+	}
+	assert.Equal(t, len(names), batch.Len())
+
+	require.NoError(t, batch.Execute())
+	for i, name := range names {
+		assert.Equal(t, name, results[i].Message)
+		assert.NoError(t, batch.Items()[i].Err)
+	}
+}
+
+func TestBatch_Execute_boundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	client, server, err := newClientAndServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	batch := pebbleclient.NewBatch(client, pebbleclient.BatchOptions{Concurrency: 2})
+	for i := 0; i < 8; i++ {
+		batch.Get("/hello", nil, nil)
+	}
+	require.NoError(t, batch.Execute())
+	assert.True(t, atomic.LoadInt32(&maxInFlight) <= 2)
+}
+
+func TestBatch_Execute_collectAll(t *testing.T) {
+	client, server, err := newClientAndServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/bad") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	batch := pebbleclient.NewBatch(client, pebbleclient.BatchOptions{})
+	batch.Get("/good", nil, nil)
+	batch.Get("/bad", nil, nil)
+	batch.Get("/good", nil, nil)
+	batch.Get("/bad", nil, nil)
+
+	err = batch.Execute()
+	require.Error(t, err)
+	batchErr, ok := err.(*pebbleclient.BatchError)
+	require.True(t, ok)
+	require.Len(t, batchErr.Errors, 4)
+	assert.NoError(t, batchErr.Errors[0])
+	assert.IsType(t, &pebbleclient.RequestError{}, batchErr.Errors[1])
+	assert.NoError(t, batchErr.Errors[2])
+	assert.IsType(t, &pebbleclient.RequestError{}, batchErr.Errors[3])
+	assert.Contains(t, err.Error(), "2 of 4 batch requests failed")
+}
+
+func TestBatch_Execute_failFast(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	client, server, err := newClientAndServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		seen = append(seen, req.URL.Path)
+		mu.Unlock()
+		if strings.HasSuffix(req.URL.Path, "/0") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	batch := pebbleclient.NewBatch(client, pebbleclient.BatchOptions{
+		Concurrency: 1,
+		FailFast:    true,
+	})
+	for i := 0; i < 5; i++ {
+		batch.Get(fmt.Sprintf("/hello/%d", i), nil, nil)
+	}
+
+	err = batch.Execute()
+	require.Error(t, err)
+	assert.IsType(t, &pebbleclient.RequestError{}, err)
+	assert.Equal(t, err, batch.Items()[0].Err)
+
+	aborted := 0
+	for _, item := range batch.Items()[1:] {
+		if item.Err == pebbleclient.ErrBatchAborted {
+			aborted++
+		}
+	}
+	assert.True(t, aborted > 0)
+	mu.Lock()
+	assert.True(t, len(seen) < 5)
+	mu.Unlock()
+}
+
+func TestBatch_Execute_empty(t *testing.T) {
+	client, err := pebbleclient.NewHTTPClient(pebbleclient.Options{
+		Host:        "localhost",
+		ServiceName: "frobnitz",
+	})
+	require.NoError(t, err)
+	assert.NoError(t, pebbleclient.NewBatch(client, pebbleclient.BatchOptions{}).Execute())
+}
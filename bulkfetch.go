@@ -0,0 +1,106 @@
+package pebbleclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxUIDListLength is the default maximum encoded length of the UIDs
+// sent in a single request by FetchByUIDs.
+const DefaultMaxUIDListLength = 2000
+
+// FetchByUIDsOptions contains options for FetchByUIDs.
+type FetchByUIDsOptions struct {
+	// Param is the name of the path parameter which receives the UIDs. Defaults
+	// to "uids".
+	Param string
+
+	// Params contains additional parameters sent with every request.
+	Params Params
+
+	// MaxLength is the maximum encoded length of the UIDs sent in a single
+	// request. Defaults to DefaultMaxUIDListLength.
+	MaxLength int
+
+	// Concurrency is the maximum number of requests executed simultaneously.
+	// Defaults to 4.
+	Concurrency int
+
+	// NewResult returns a new value to decode a single response into, such as
+	// a pointer to a struct. Required.
+	NewResult func() interface{}
+
+	// Extract returns the items contained in a decoded response, keyed by UID.
+	// Required.
+	Extract func(result interface{}) map[UID]interface{}
+}
+
+// FetchByUIDsResult is the result of FetchByUIDs.
+type FetchByUIDsResult struct {
+	// Items contains the items that were found, in the order of the input UIDs.
+	Items []interface{}
+
+	// Missing contains the UIDs that were not found, in input order.
+	Missing UIDList
+}
+
+// FetchByUIDs fetches many items by UID. The UIDs are deduplicated and split
+// into chunks that keep request URLs short enough, which are then fetched
+// concurrently from a path such as "/posts/:uids". If any request fails, the
+// first error is returned. Only exact UIDs are supported, since results are
+// matched to the input UIDs.
+func FetchByUIDs(client Client, path string, uids UIDList, opts FetchByUIDsOptions) (*FetchByUIDsResult, error) {
+	if opts.NewResult == nil || opts.Extract == nil {
+		return nil, errors.New("FetchByUIDs requires NewResult and Extract")
+	}
+	if opts.Param == "" {
+		opts.Param = "uids"
+	}
+	if !strings.Contains(path+"/", "/:"+opts.Param+"/") {
+		return nil, fmt.Errorf("Path %q has no :%s parameter", path, opts.Param)
+	}
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = DefaultMaxUIDListLength
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	uids = uids.Dedup()
+
+	batch := NewBatch(client, BatchOptions{
+		Concurrency: opts.Concurrency,
+		FailFast:    true,
+	})
+	for _, chunk := range uids.Chunk(opts.MaxLength) {
+		params := make(Params, len(opts.Params)+1)
+		for k, v := range opts.Params {
+			params[k] = v
+		}
+		params[opts.Param] = chunk
+		batch.Get(path, &RequestOptions{Params: params}, opts.NewResult())
+	}
+	if err := batch.Execute(); err != nil {
+		return nil, err
+	}
+
+	found := make(map[UID]interface{}, len(uids))
+	for _, item := range batch.Items() {
+		for uid, v := range opts.Extract(item.Result) {
+			found[uid] = v
+		}
+	}
+
+	result := &FetchByUIDsResult{
+		Items: make([]interface{}, 0, len(found)),
+	}
+	for _, uid := range uids {
+		if v, ok := found[uid]; ok {
+			result.Items = append(result.Items, v)
+		} else {
+			result.Missing = append(result.Missing, uid)
+		}
+	}
+	return result, nil
+}
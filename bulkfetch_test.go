@@ -0,0 +1,113 @@
+package pebbleclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pebble "github.com/t11e/go-pebbleclient"
+)
+
+type bulkPost struct {
+	UID pebble.UID `json:"uid"`
+}
+
+type bulkPostsResponse struct {
+	Posts []bulkPost `json:"posts"`
+}
+
+func bulkPostsOptions() pebble.FetchByUIDsOptions {
+	return pebble.FetchByUIDsOptions{
+		NewResult: func() interface{} {
+			return &bulkPostsResponse{}
+		},
+		Extract: func(result interface{}) map[pebble.UID]interface{} {
+			items := map[pebble.UID]interface{}{}
+			for _, post := range result.(*bulkPostsResponse).Posts {
+				items[post.UID] = post
+			}
+			return items
+		},
+	}
+}
+
+func TestFetchByUIDs(t *testing.T) {
+	var requests int32
+	client, server, err := newClientAndServer(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "true", req.URL.Query().Get("raw"))
+		uids := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/frobnitz/v1/posts/"), ",")
+		assert.True(t, len(uids) <= 3, "too many UIDs in one request")
+
+		// Pretend that odd UIDs don't exist, and respond in reverse order
+		var posts []bulkPost
+		for i := len(uids) - 1; i >= 0; i-- {
+			nuid, err := pebble.UID(uids[i]).NUID()
+			require.NoError(t, err)
+			if nuid%2 == 0 {
+				posts = append(posts, bulkPost{UID: pebble.UID(uids[i])})
+			}
+		}
+		writeJSONDatum(w, http.StatusOK, map[string]interface{}{"posts": posts})
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	var uids pebble.UIDList
+	for i := 20; i > 0; i-- {
+		uids = append(uids, pebble.UID(fmt.Sprintf("post:acme$%d", i)))
+	}
+	uids = append(uids, "post:acme$20")
+
+	opts := bulkPostsOptions()
+	opts.Params = pebble.Params{"raw": true}
+	// Room for three UIDs of up to 16 encoded bytes, joined by 3-byte commas
+	opts.MaxLength = 54
+	result, err := pebble.FetchByUIDs(client, "/posts/:uids", uids, opts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), atomic.LoadInt32(&requests))
+
+	require.Len(t, result.Items, 10)
+	for i, item := range result.Items {
+		assert.Equal(t, pebble.UID(fmt.Sprintf("post:acme$%d", 20-2*i)), item.(bulkPost).UID)
+	}
+	require.Len(t, result.Missing, 10)
+	for i, uid := range result.Missing {
+		assert.Equal(t, pebble.UID(fmt.Sprintf("post:acme$%d", 19-2*i)), uid)
+	}
+}
+
+func TestFetchByUIDs_error(t *testing.T) {
+	client, server, err := newClientAndServer(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	_, err = pebble.FetchByUIDs(client, "/posts/:uids", pebble.UIDList{"post:acme$1"}, bulkPostsOptions())
+	require.Error(t, err)
+	assert.IsType(t, &pebble.RequestError{}, err)
+}
+
+func TestFetchByUIDs_invalidOptions(t *testing.T) {
+	client, err := pebble.NewHTTPClient(pebble.Options{Host: "localhost"})
+	require.NoError(t, err)
+
+	_, err = pebble.FetchByUIDs(client, "/posts/:uids", nil, pebble.FetchByUIDsOptions{})
+	assert.EqualError(t, err, "FetchByUIDs requires NewResult and Extract")
+
+	_, err = pebble.FetchByUIDs(client, "/posts/:uid", nil, bulkPostsOptions())
+	assert.EqualError(t, err, `Path "/posts/:uid" has no :uids parameter`)
+
+	opts := bulkPostsOptions()
+	opts.Param = "uid"
+	result, err := pebble.FetchByUIDs(client, "/posts/:uid", nil, opts)
+	require.NoError(t, err)
+	assert.Empty(t, result.Items)
+	assert.Empty(t, result.Missing)
+}
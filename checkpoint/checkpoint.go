@@ -0,0 +1,146 @@
+// Package checkpoint is a client for Checkpoint, the identity and session
+// service.
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+//go:generate go run ../vendor/github.com/vektra/mockery/cmd/mockery/mockery.go -name=Client -case=underscore
+
+// ErrAnonymous is returned when an operation requires an identity, but the
+// session is anonymous or missing.
+var ErrAnonymous = errors.New("Session is anonymous")
+
+// Identity is a Checkpoint identity.
+type Identity struct {
+	ID               int        `json:"id"`
+	Realm            string     `json:"realm"`
+	God              bool       `json:"god"`
+	PrimaryAccountID int        `json:"primary_account_id,omitempty"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// IsAnonymous returns true if this is not a real identity.
+func (identity *Identity) IsAnonymous() bool {
+	return identity == nil || identity.ID == 0
+}
+
+// IsMemberOf returns true if the identity belongs to a realm.
+func (identity *Identity) IsMemberOf(realm string) bool {
+	return !identity.IsAnonymous() && identity.Realm == realm
+}
+
+type identityResponse struct {
+	Identity *Identity `json:"identity"`
+}
+
+type identitiesResponse struct {
+	Identities []*Identity `json:"identities"`
+}
+
+// Client is a client for Checkpoint.
+type Client interface {
+	// Me returns the identity of the client's session. Returns ErrAnonymous if
+	// the session is anonymous or missing.
+	Me() (*Identity, error)
+
+	// GetIdentity returns an identity by ID.
+	GetIdentity(id int) (*Identity, error)
+
+	// GetIdentities returns identities by ID. Identities that don't exist are
+	// omitted.
+	GetIdentities(ids []int) ([]*Identity, error)
+
+	// IsGod returns true if the client's session has god mode. Returns
+	// ErrAnonymous if the session is anonymous or missing.
+	IsGod() (bool, error)
+
+	// IsMemberOf returns true if the client's session belongs to a realm.
+	// Returns ErrAnonymous if the session is anonymous or missing.
+	IsMemberOf(realm string) (bool, error)
+}
+
+type client struct {
+	c pc.Client
+}
+
+// New constructs a new Checkpoint client.
+func New(c pc.Client) (Client, error) {
+	return &client{c.WithOptions(pc.Options{
+		ServiceName: "checkpoint",
+		APIVersion:  1,
+	})}, nil
+}
+
+// Register registers the Checkpoint client with a connector, so that it can be
+// obtained with Connect.
+func Register(connector *pc.Connector) {
+	connector.Register((*Client)(nil), func(c pc.Client) (pc.Service, error) {
+		return New(c)
+	})
+}
+
+func (c *client) Me() (*Identity, error) {
+	var out identityResponse
+	if err := c.c.Get("/identities/me", nil, &out); err != nil {
+		return nil, err
+	}
+	if out.Identity.IsAnonymous() {
+		return nil, ErrAnonymous
+	}
+	return out.Identity, nil
+}
+
+func (c *client) GetIdentity(id int) (*Identity, error) {
+	var out identityResponse
+	if err := c.c.Get("/identities/:id", &pc.RequestOptions{
+		Params: pc.Params{"id": id},
+	}, &out); err != nil {
+		return nil, err
+	}
+	if out.Identity == nil {
+		return nil, fmt.Errorf("No identity in response for ID %d", id)
+	}
+	return out.Identity, nil
+}
+
+func (c *client) GetIdentities(ids []int) ([]*Identity, error) {
+	if len(ids) == 0 {
+		return []*Identity{}, nil
+	}
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.Itoa(id)
+	}
+	var out identitiesResponse
+	if err := c.c.Get("/identities", &pc.RequestOptions{
+		Params: pc.Params{"ids": strings.Join(strs, ",")},
+	}, &out); err != nil {
+		return nil, err
+	}
+	return out.Identities, nil
+}
+
+func (c *client) IsGod() (bool, error) {
+	identity, err := c.Me()
+	if err != nil {
+		return false, err
+	}
+	return identity.God, nil
+}
+
+func (c *client) IsMemberOf(realm string) (bool, error) {
+	identity, err := c.Me()
+	if err != nil {
+		return false, err
+	}
+	return identity.IsMemberOf(realm), nil
+}
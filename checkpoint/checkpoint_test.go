@@ -0,0 +1,152 @@
+package checkpoint_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/checkpoint"
+)
+
+func newClientAndServer(t *testing.T, handler http.HandlerFunc) (checkpoint.Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client, err := pc.NewHTTPClient(pc.Options{
+		Host:    u.Host,
+		Session: "uio3ui3ui3",
+	})
+	require.NoError(t, err)
+
+	cp, err := checkpoint.New(client)
+	require.NoError(t, err)
+	return cp, server
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestClient_Me(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/checkpoint/v1/identities/me", req.URL.Path)
+		cookie, err := req.Cookie("checkpoint.session")
+		require.NoError(t, err)
+		assert.Equal(t, "uio3ui3ui3", cookie.Value)
+		writeJSON(w, map[string]interface{}{
+			"identity": map[string]interface{}{
+				"id":    42,
+				"realm": "acme",
+				"god":   true,
+			},
+		})
+	})
+	defer server.Close()
+
+	identity, err := client.Me()
+	require.NoError(t, err)
+	assert.Equal(t, 42, identity.ID)
+	assert.Equal(t, "acme", identity.Realm)
+	assert.True(t, identity.God)
+
+	god, err := client.IsGod()
+	require.NoError(t, err)
+	assert.True(t, god)
+
+	member, err := client.IsMemberOf("acme")
+	require.NoError(t, err)
+	assert.True(t, member)
+
+	member, err = client.IsMemberOf("other")
+	require.NoError(t, err)
+	assert.False(t, member)
+}
+
+func TestClient_Me_anonymous(t *testing.T) {
+	for _, body := range []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"identity": map[string]interface{}{}},
+	} {
+		client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+			writeJSON(w, body)
+		})
+
+		_, err := client.Me()
+		assert.Equal(t, checkpoint.ErrAnonymous, err)
+		_, err = client.IsGod()
+		assert.Equal(t, checkpoint.ErrAnonymous, err)
+		_, err = client.IsMemberOf("acme")
+		assert.Equal(t, checkpoint.ErrAnonymous, err)
+
+		server.Close()
+	}
+}
+
+func TestClient_GetIdentity(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/checkpoint/v1/identities/7", req.URL.Path)
+		writeJSON(w, map[string]interface{}{
+			"identity": map[string]interface{}{"id": 7, "realm": "acme"},
+		})
+	})
+	defer server.Close()
+
+	identity, err := client.GetIdentity(7)
+	require.NoError(t, err)
+	assert.Equal(t, 7, identity.ID)
+}
+
+func TestClient_GetIdentity_notFound(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	_, err := client.GetIdentity(7)
+	require.Error(t, err)
+	assert.IsType(t, &pc.RequestError{}, err)
+}
+
+func TestClient_GetIdentities(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/checkpoint/v1/identities", req.URL.Path)
+		assert.Equal(t, "1,2,3", req.URL.Query().Get("ids"))
+		writeJSON(w, map[string]interface{}{
+			"identities": []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 3},
+			},
+		})
+	})
+	defer server.Close()
+
+	identities, err := client.GetIdentities([]int{1, 2, 3})
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	assert.Equal(t, 1, identities[0].ID)
+	assert.Equal(t, 3, identities[1].ID)
+
+	identities, err = client.GetIdentities(nil)
+	require.NoError(t, err)
+	assert.Empty(t, identities)
+}
+
+func TestRegister(t *testing.T) {
+	connector, err := pc.NewConnectorFromConfig(pc.RealmsConfig{
+		"acme": &pc.RealmConfig{Host: "example.com"},
+	})
+	require.NoError(t, err)
+	checkpoint.Register(connector)
+
+	var client checkpoint.Client
+	require.NoError(t, connector.Connect(&client))
+	assert.NotNil(t, client)
+}
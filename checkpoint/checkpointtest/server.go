@@ -0,0 +1,130 @@
+// Package checkpointtest provides a fake Checkpoint server for tests.
+package checkpointtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/checkpoint"
+)
+
+const prefix = "/api/checkpoint/v1"
+
+// Server is a fake Checkpoint server. It knows about a set of identities, and
+// which sessions belong to them. Unknown sessions are anonymous.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	identities map[int]*checkpoint.Identity
+	sessions   map[string]int
+	requests   int32
+}
+
+// NewServer starts a new fake Checkpoint server. It must be closed after use.
+func NewServer() *Server {
+	server := &Server{
+		identities: map[int]*checkpoint.Identity{},
+		sessions:   map[string]int{},
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+// AddIdentity adds an identity, and any sessions belonging to it.
+func (server *Server) AddIdentity(identity *checkpoint.Identity, sessions ...string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.identities[identity.ID] = identity
+	for _, session := range sessions {
+		server.sessions[session] = identity.ID
+	}
+}
+
+// RemoveSession makes a session anonymous.
+func (server *Server) RemoveSession(session string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	delete(server.sessions, session)
+}
+
+// Requests returns the number of requests the server has received.
+func (server *Server) Requests() int {
+	return int(atomic.LoadInt32(&server.requests))
+}
+
+// Host returns the server's host and port.
+func (server *Server) Host() string {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		panic(err)
+	}
+	return u.Host
+}
+
+// NewClient returns a client for the server, with a session.
+func (server *Server) NewClient(session string) pc.Client {
+	client, err := pc.NewHTTPClient(pc.Options{
+		Host:    server.Host(),
+		Session: session,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	atomic.AddInt32(&server.requests, 1)
+
+	path := strings.TrimPrefix(req.URL.Path, prefix)
+	if path == req.URL.Path || req.Method != "GET" {
+		http.NotFound(w, req)
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	switch {
+	case path == "/identities/me":
+		session, _ := pc.ExtractSession(req, nil)
+		identity := &checkpoint.Identity{}
+		if id, ok := server.sessions[session]; ok {
+			identity = server.identities[id]
+		}
+		writeJSON(w, map[string]interface{}{"identity": identity})
+	case path == "/identities":
+		identities := []*checkpoint.Identity{}
+		for _, s := range strings.Split(req.URL.Query().Get("ids"), ",") {
+			if id, err := strconv.Atoi(s); err == nil {
+				if identity, ok := server.identities[id]; ok {
+					identities = append(identities, identity)
+				}
+			}
+		}
+		writeJSON(w, map[string]interface{}{"identities": identities})
+	case strings.HasPrefix(path, "/identities/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(path, "/identities/"))
+		identity, ok := server.identities[id]
+		if err != nil || !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"identity": identity})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,49 @@
+package checkpointtest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/t11e/go-pebbleclient/checkpoint"
+	"github.com/t11e/go-pebbleclient/checkpoint/checkpointtest"
+)
+
+func TestServer(t *testing.T) {
+	server := checkpointtest.NewServer()
+	defer server.Close()
+	server.AddIdentity(&checkpoint.Identity{ID: 1, Realm: "acme", God: true}, "s1", "s2")
+	server.AddIdentity(&checkpoint.Identity{ID: 2, Realm: "acme"})
+
+	for _, session := range []string{"s1", "s2"} {
+		cp, err := checkpoint.New(server.NewClient(session))
+		require.NoError(t, err)
+		identity, err := cp.Me()
+		require.NoError(t, err)
+		assert.Equal(t, 1, identity.ID)
+	}
+
+	cp, err := checkpoint.New(server.NewClient("unknown"))
+	require.NoError(t, err)
+	_, err = cp.Me()
+	assert.Equal(t, checkpoint.ErrAnonymous, err)
+
+	identity, err := cp.GetIdentity(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, identity.ID)
+	_, err = cp.GetIdentity(3)
+	assert.Error(t, err)
+
+	identities, err := cp.GetIdentities([]int{1, 2, 3})
+	require.NoError(t, err)
+	assert.Len(t, identities, 2)
+
+	server.RemoveSession("s1")
+	cp, err = checkpoint.New(server.NewClient("s1"))
+	require.NoError(t, err)
+	_, err = cp.Me()
+	assert.Equal(t, checkpoint.ErrAnonymous, err)
+
+	assert.Equal(t, 7, server.Requests())
+}
@@ -0,0 +1,120 @@
+package mocks
+
+import checkpoint "github.com/t11e/go-pebbleclient/checkpoint"
+import mock "github.com/stretchr/testify/mock"
+
+// Client is an autogenerated mock type for the Client type
+type Client struct {
+	mock.Mock
+}
+
+// GetIdentities provides a mock function with given fields: ids
+func (_m *Client) GetIdentities(ids []int) ([]*checkpoint.Identity, error) {
+	ret := _m.Called(ids)
+
+	var r0 []*checkpoint.Identity
+	if rf, ok := ret.Get(0).(func([]int) []*checkpoint.Identity); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*checkpoint.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]int) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentity provides a mock function with given fields: id
+func (_m *Client) GetIdentity(id int) (*checkpoint.Identity, error) {
+	ret := _m.Called(id)
+
+	var r0 *checkpoint.Identity
+	if rf, ok := ret.Get(0).(func(int) *checkpoint.Identity); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*checkpoint.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsGod provides a mock function with given fields:
+func (_m *Client) IsGod() (bool, error) {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsMemberOf provides a mock function with given fields: realm
+func (_m *Client) IsMemberOf(realm string) (bool, error) {
+	ret := _m.Called(realm)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(realm)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(realm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Me provides a mock function with given fields:
+func (_m *Client) Me() (*checkpoint.Identity, error) {
+	ret := _m.Called()
+
+	var r0 *checkpoint.Identity
+	if rf, ok := ret.Get(0).(func() *checkpoint.Identity); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*checkpoint.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,199 @@
+package checkpoint
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"golang.org/x/net/context"
+)
+
+var errNoClient = errors.New("No Checkpoint client configured or found in request context")
+
+type identityContextKey struct{}
+
+// ContextWithIdentity returns a new context which carries an identity.
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the identity carried by a context, if any. It is
+// set by Validator.Handler for requests with a valid session.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	identity, ok := ctx.Value(identityContextKey{}).(*Identity)
+	return identity, ok && !identity.IsAnonymous()
+}
+
+// ValidatorOptions contains options for NewValidator.
+type ValidatorOptions struct {
+	// Client is used to talk to Checkpoint. If nil, the client stored in the
+	// request's context by pc.Connector.Handler is used.
+	Client pc.Client
+
+	// CacheTTL is how long a valid session is remembered. Defaults to one
+	// minute. Anonymous sessions and failures are never cached.
+	CacheTTL time.Duration
+
+	// SessionExtractors finds the session in incoming requests. If nil,
+	// pc.DefaultSessionExtractors are used.
+	SessionExtractors []pc.SessionExtractor
+
+	// Now returns the current time, for expiring cached sessions. Defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
+// Validator validates the sessions of incoming requests against Checkpoint.
+type Validator struct {
+	opts ValidatorOptions
+
+	mu    sync.Mutex
+	cache map[string]cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity *Identity
+	expires  time.Time
+}
+
+// NewValidator constructs a new validator.
+func NewValidator(opts ValidatorOptions) *Validator {
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = time.Minute
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Validator{
+		opts:  opts,
+		cache: map[string]cachedIdentity{},
+	}
+}
+
+// Handler returns HTTP middleware which validates the session of each incoming
+// request, and stores the resolved identity in the request's context, where it
+// can be retrieved with IdentityFromContext. Requests without a session, or with
+// an anonymous one, are passed on without an identity; use RequireLogin or
+// RequireGod to reject them. If Checkpoint cannot be reached, or fails, 502 is
+// returned. If no Checkpoint client is configured or found in the request's
+// context, which is a server misconfiguration, 500 is returned.
+func (validator *Validator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, err := validator.Validate(req)
+		if err == errNoClient {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if identity != nil {
+			req = req.WithContext(ContextWithIdentity(req.Context(), identity))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Validate resolves the identity of a request's session. Returns a nil identity
+// if the request has no session, or the session is anonymous.
+func (validator *Validator) Validate(req *http.Request) (*Identity, error) {
+	session, ok := pc.ExtractSession(req, validator.opts.SessionExtractors)
+	if !ok {
+		return nil, nil
+	}
+
+	client := validator.opts.Client
+	if client == nil {
+		if client, ok = pc.ClientFromContext(req.Context()); !ok {
+			return nil, errNoClient
+		}
+	}
+
+	key := client.GetOptions().Host + "\x00" + session
+	if identity, ok := validator.lookup(key); ok {
+		return identity, nil
+	}
+
+	cp, err := New(client.WithOptions(pc.Options{
+		Session: session,
+		Ctx:     req.Context(),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	identity, err := cp.Me()
+	if err == ErrAnonymous {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	validator.store(key, identity)
+	return identity, nil
+}
+
+func (validator *Validator) lookup(key string) (*Identity, bool) {
+	validator.mu.Lock()
+	defer validator.mu.Unlock()
+	entry, ok := validator.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if !validator.opts.Now().Before(entry.expires) {
+		delete(validator.cache, key)
+		return nil, false
+	}
+	return entry.identity, true
+}
+
+func (validator *Validator) store(key string, identity *Identity) {
+	validator.mu.Lock()
+	defer validator.mu.Unlock()
+	now := validator.opts.Now()
+	for k, entry := range validator.cache {
+		if !now.Before(entry.expires) {
+			delete(validator.cache, k)
+		}
+	}
+	validator.cache[key] = cachedIdentity{
+		identity: identity,
+		expires:  now.Add(validator.opts.CacheTTL),
+	}
+}
+
+// RequireLogin returns HTTP middleware which responds with 401 unless the
+// request has an identity. It must be wrapped by Validator.Handler.
+func RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := IdentityFromContext(req.Context()); !ok {
+			http.Error(w, ErrAnonymous.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// RequireGod returns HTTP middleware which responds with 401 unless the request
+// has an identity, and 403 unless that identity has god mode. It must be
+// wrapped by Validator.Handler.
+func RequireGod(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, ok := IdentityFromContext(req.Context())
+		if !ok {
+			http.Error(w, ErrAnonymous.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !identity.God {
+			http.Error(w, "God mode required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
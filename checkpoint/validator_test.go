@@ -0,0 +1,147 @@
+package checkpoint_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/checkpoint"
+	"github.com/t11e/go-pebbleclient/checkpoint/checkpointtest"
+)
+
+func newFakeCheckpoint() *checkpointtest.Server {
+	server := checkpointtest.NewServer()
+	server.AddIdentity(&checkpoint.Identity{ID: 1, Realm: "acme"}, "mortal")
+	server.AddIdentity(&checkpoint.Identity{ID: 2, Realm: "acme", God: true}, "god")
+	return server
+}
+
+func serveWithSession(handler http.Handler, session string) *httptest.ResponseRecorder {
+	return serveHostWithSession(handler, "example.com", session)
+}
+
+func serveHostWithSession(handler http.Handler, host, session string) *httptest.ResponseRecorder {
+	target := "http://" + host + "/"
+	if session != "" {
+		target += "?session=" + session
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+func TestValidator_Handler(t *testing.T) {
+	server := newFakeCheckpoint()
+	defer server.Close()
+
+	validator := checkpoint.NewValidator(checkpoint.ValidatorOptions{
+		Client: server.NewClient(""),
+	})
+
+	var identity *checkpoint.Identity
+	var found bool
+	handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, found = checkpoint.IdentityFromContext(req.Context())
+	}))
+
+	w := serveWithSession(handler, "mortal")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.True(t, found)
+	assert.Equal(t, 1, identity.ID)
+
+	for _, session := range []string{"", "unknown"} {
+		w = serveWithSession(handler, session)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, found)
+	}
+}
+
+func TestValidator_Handler_cache(t *testing.T) {
+	server := newFakeCheckpoint()
+	defer server.Close()
+
+	now := time.Date(2017, 5, 1, 12, 0, 0, 0, time.UTC)
+	validator := checkpoint.NewValidator(checkpoint.ValidatorOptions{
+		Client:   server.NewClient(""),
+		CacheTTL: time.Minute,
+		Now: func() time.Time {
+			return now
+		},
+	})
+	handler := validator.Handler(checkpoint.RequireLogin(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})))
+
+	assert.Equal(t, http.StatusOK, serveWithSession(handler, "mortal").Code)
+	assert.Equal(t, http.StatusOK, serveWithSession(handler, "mortal").Code)
+	assert.Equal(t, 1, server.Requests())
+
+	// Anonymous sessions are not cached
+	assert.Equal(t, http.StatusUnauthorized, serveWithSession(handler, "unknown").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithSession(handler, "unknown").Code)
+	assert.Equal(t, 3, server.Requests())
+
+	server.RemoveSession("mortal")
+	now = now.Add(59 * time.Second)
+	assert.Equal(t, http.StatusOK, serveWithSession(handler, "mortal").Code)
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusUnauthorized, serveWithSession(handler, "mortal").Code)
+	assert.Equal(t, 4, server.Requests())
+}
+
+func TestValidator_Handler_clientFromContext(t *testing.T) {
+	server := newFakeCheckpoint()
+	defer server.Close()
+
+	connector, err := pc.NewConnectorFromConfig(pc.RealmsConfig{
+		"acme": &pc.RealmConfig{Host: server.Host()},
+	})
+	require.NoError(t, err)
+
+	validator := checkpoint.NewValidator(checkpoint.ValidatorOptions{})
+	var identity *checkpoint.Identity
+	handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		identity, _ = checkpoint.IdentityFromContext(req.Context())
+	}))
+
+	w := serveWithSession(handler, "god")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = serveHostWithSession(connector.Handler(handler, pc.ConnectorHandlerOptions{}), server.Host(), "god")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, identity)
+	assert.Equal(t, 2, identity.ID)
+}
+
+func TestValidator_Handler_checkpointDown(t *testing.T) {
+	server := newFakeCheckpoint()
+	client := server.NewClient("")
+	server.Close()
+
+	validator := checkpoint.NewValidator(checkpoint.ValidatorOptions{
+		Client: client,
+	})
+	handler := validator.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+	assert.Equal(t, http.StatusBadGateway, serveWithSession(handler, "mortal").Code)
+}
+
+func TestRequireGod(t *testing.T) {
+	server := newFakeCheckpoint()
+	defer server.Close()
+
+	validator := checkpoint.NewValidator(checkpoint.ValidatorOptions{
+		Client: server.NewClient(""),
+	})
+	handler := validator.Handler(checkpoint.RequireGod(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})))
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithSession(handler, "").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithSession(handler, "mortal").Code)
+	assert.Equal(t, http.StatusOK, serveWithSession(handler, "god").Code)
+}
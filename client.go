@@ -7,7 +7,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/jpillora/backoff"
@@ -41,28 +40,25 @@ func NewHTTPClient(opts Options) (*HTTPClient, error) {
 }
 
 // FromHTTPRequest constructs a new client that inherits the host name, protocol,
-// session and request ID from an HTTP request. Any options specified will override inferred
-// from the request.
+// session and request ID from an HTTP request. The host name and protocol are
+// taken from the Forwarded or X-Forwarded-* headers if the request comes from a
+// trusted proxy; see Options.TrustedProxies. The host name is normalized, and
+// keeps its port unless it's the default for the protocol. The session is found
+// with Options.SessionExtractors. Any options specified will override inferred
+// from the request. The request ID is taken from the request's context (see
+// RequestIDHandler), or else the Request-Id or X-Request-Id headers.
 func (client *HTTPClient) FromHTTPRequest(req *http.Request) (*HTTPClient, error) {
 	opts := Options(client.options)
 
-	var host string
-	if hosts, ok := req.Header["X-Forwarded-Host"]; ok && len(hosts) > 0 {
-		host = hosts[len(hosts)-1]
-	} else {
-		host = strings.SplitN(req.Host, ":", 2)[0]
-	}
-	opts.Host = host
-
-	opts.Protocol = req.URL.Scheme
+	origin := inferRequestOrigin(req, client.TrustedProxies)
+	opts.Host = origin.host
+	opts.Protocol = origin.proto
 
-	if session := req.URL.Query().Get("session"); session != "" {
+	if session, ok := ExtractSession(req, client.SessionExtractors); ok {
 		opts.Session = session
-	} else if cookie, err := req.Cookie("checkpoint.session"); err == nil {
-		opts.Session = cookie.Value
 	}
 
-	if id := req.Header.Get("Request-Id"); id != "" {
+	if id, ok := requestIDFromRequest(req); ok {
 		opts.RequestID = id
 	}
 
@@ -131,14 +127,9 @@ func (client *HTTPClient) Do(
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	if client.options.RequestID != "" {
-		req.Header.Set("Request-Id", client.options.RequestID)
-	}
+	req.Header.Set("Request-Id", client.requestID())
 	if client.Session != "" {
-		req.AddCookie(&http.Cookie{
-			Name:  "checkpoint.session",
-			Value: client.Session,
-		})
+		setSession(req, client.Session, client.SessionHeader)
 	}
 
 	ctx := client.Ctx
@@ -146,43 +137,132 @@ func (client *HTTPClient) Do(
 		ctx = context.Background()
 	}
 
+	ctx, span := client.startCallSpan(ctx, method, path)
+	start := time.Now()
+
 	boff := &backoff.Backoff{
 		Jitter: true,
 	}
 
-	for {
-		resp, err := ctxhttp.Do(ctx, client.hc, req)
+	for attempt := 1; ; attempt++ {
+		statusCode, retriable, err := client.attempt(ctx, attempt, opts, req, result)
+		if err != nil && retriable {
+			select {
+			case <-ctx.Done():
+				// Let error handling below handle this
+			case <-time.After(boff.Duration()):
+				continue
+			}
+		}
+		endSpan(span, statusCode, err)
+		if client.Metrics != nil {
+			client.Metrics.ObserveCall(CallMetrics{
+				ServiceName:  client.ServiceName,
+				Method:       method,
+				PathTemplate: normalizePathTemplate(path),
+				StatusCode:   statusCode,
+				Duration:     time.Since(start),
+				Attempts:     attempt,
+				ErrorClass:   ClassifyError(err),
+			})
+		}
+		return err
+	}
+}
+
+// attempt performs a single attempt at a request. Returns the status code, if a
+// response was received, and true if the request failed with an error that can
+// be retried.
+func (client *HTTPClient) attempt(
+	ctx context.Context,
+	attempt int,
+	opts *RequestOptions,
+	req *http.Request,
+	result interface{}) (statusCode int, retriable bool, err error) {
+	ctx, span := client.startAttemptSpan(ctx, req, attempt)
+	start := time.Now()
+	defer func() {
+		endSpan(span, statusCode, err)
+		client.logAttempt(ctx, req, attempt, statusCode, time.Since(start), err)
+	}()
+
+	if client.RateLimiter != nil {
+		if err := client.RateLimiter.Wait(ctx, client.Host, client.ServiceName); err != nil {
+			return 0, false, err
+		}
+	}
+
+	if client.ConcurrencyLimiter != nil {
+		release, err := client.ConcurrencyLimiter.Acquire(ctx, client.Host, client.ServiceName)
 		if err != nil {
-			return err
+			return 0, false, err
+		}
+		defer release()
+	}
+
+	resp, err := client.send(ctx, req)
+	if err != nil {
+		return 0, false, err
+	}
+
+	statusCode = resp.StatusCode
+
+	respBody := resp.Body
+	defer func() {
+		if respBody != nil {
+			// Drain remaining body to work around bug in Go < 1.7
+			_, _ = io.Copy(ioutil.Discard, respBody)
+
+			_ = respBody.Close()
 		}
+	}()
 
-		respBody := resp.Body
-		defer func() {
-			if respBody != nil {
-				// Drain remaining body to work around bug in Go < 1.7
-				_, _ = io.Copy(ioutil.Discard, respBody)
+	if isNonSuccessStatus(resp.StatusCode) {
+		return statusCode, isRetriableStatus(resp.StatusCode),
+			client.buildError(&RequestError{}, opts, req, resp)
+	}
 
-				_ = respBody.Close()
-			}
-		}()
-
-		if isNonSuccessStatus(resp.StatusCode) {
-			if isRetriableStatus(resp.StatusCode) {
-				select {
-				case <-ctx.Done():
-					// Let error handling below handle this
-				case <-time.After(boff.Duration()):
-					continue
-				}
-			}
-			return client.buildError(&RequestError{}, opts, req, resp)
+	if doesStatusCodeYieldBody(resp.StatusCode) && result != nil {
+		if err := decodeResponseAsJSON(resp, respBody, result); err != nil {
+			return statusCode, false, &DecodeError{err}
 		}
+	}
+	return statusCode, false, nil
+}
 
-		if doesStatusCodeYieldBody(resp.StatusCode) && result != nil {
-			return decodeResponseAsJSON(resp, respBody, result)
+// send sends a request through the middleware chain. Each attempt gets its own
+// copy of the request, so that changes made by middleware, such as added
+// headers, do not carry over into retries.
+func (client *HTTPClient) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	handler := Handler(func(req *http.Request) (*http.Response, error) {
+		if client.Hedging != nil && req.Method == "GET" {
+			return client.Hedging.do(req.Context(), client.hc, req)
+		}
+		return ctxhttp.Do(req.Context(), client.hc, req)
+	})
+	for i := len(client.Middleware) - 1; i >= 0; i-- {
+		middleware, next := client.Middleware[i], handler
+		handler = func(req *http.Request) (*http.Response, error) {
+			return middleware.Handle(req, next)
 		}
-		return nil
 	}
+	resp, err := handler(req.Clone(ctx))
+	if err == nil && resp == nil {
+		return nil, errors.New("Middleware returned neither a response nor an error")
+	}
+	return resp, err
+}
+
+// requestID returns the request ID to send: the one set in the options, else
+// the one carried by the context, else a new one.
+func (client *HTTPClient) requestID() string {
+	if client.options.RequestID != "" {
+		return client.options.RequestID
+	}
+	if id, ok := RequestIDFromContext(client.Ctx); ok {
+		return id
+	}
+	return NewRequestID()
 }
 
 func (client *HTTPClient) buildError(
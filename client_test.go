@@ -347,3 +347,32 @@ func writeJSONDatum(w http.ResponseWriter, statusCode int, datum interface{}) {
 	encoder := json.NewEncoder(w)
 	encoder.Encode(datum)
 }
+
+func TestClient_FromHTTPRequest_forwarded(t *testing.T) {
+	req, err := http.NewRequest("GET", "/", bytes.NewReader([]byte{}))
+	assert.NoError(t, err)
+	req.Host = "internal:8080"
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-Host", "example.com")
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	trusted, err := pebbleclient.ParseTrustedProxies("10.0.0.0/8")
+	assert.NoError(t, err)
+
+	client, err := pebbleclient.NewHTTPClient(pebbleclient.Options{
+		ServiceName:    "frobnitz",
+		TrustedProxies: trusted,
+	})
+	assert.NoError(t, err)
+
+	forwarded, err := client.FromHTTPRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", forwarded.GetOptions().Host)
+	assert.Equal(t, "https", forwarded.GetOptions().Protocol)
+
+	req.RemoteAddr = "1.2.3.4:1234"
+	direct, err := client.FromHTTPRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "internal:8080", direct.GetOptions().Host)
+	assert.Equal(t, "http", direct.GetOptions().Protocol)
+}
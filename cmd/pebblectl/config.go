@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+const (
+	configEnv  = "PEBBLECTL_CONFIG"
+	sessionEnv = "PEBBLE_SESSION"
+)
+
+// defaultConfigPath returns the path of the realms config file: the value of
+// $PEBBLECTL_CONFIG, or else ~/.pebblectl.yml.
+func defaultConfigPath(getenv func(string) string) string {
+	if path := getenv(configEnv); path != "" {
+		return path
+	}
+	if home := getenv("HOME"); home != "" {
+		return filepath.Join(home, ".pebblectl.yml")
+	}
+	return ""
+}
+
+// loadRealmsConfig reads a realms config file, in YAML or JSON, mapping realm
+// names to their host, aliases and session. If optional is true, a missing file
+// yields an empty config.
+func loadRealmsConfig(path string, optional bool) (pc.RealmsConfig, error) {
+	config := pc.RealmsConfig{}
+	if path == "" {
+		return config, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if optional && os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("Could not parse %s: %s", path, err)
+	}
+	return config, nil
+}
+
+// resolveTarget determines the host and session to use. A realm may be given
+// by name, or by one of its hosts or aliases. An explicit host or session takes
+// precedence; otherwise they come from the realm's config. The session may also
+// be given in $PEBBLE_SESSION.
+func resolveTarget(config pc.RealmsConfig, realm, host, session string, getenv func(string) string) (string, string, error) {
+	if realm != "" {
+		realmConfig, ok := config[realm]
+		if !ok {
+			if realmConfig = config.FindByHost(realm); realmConfig == nil {
+				return "", "", &pc.NoRealmConfigError{Realm: realm}
+			}
+		}
+		if host == "" {
+			host = realmConfig.Host
+		}
+		if session == "" {
+			session = getenv(sessionEnv)
+		}
+		if session == "" {
+			session = realmConfig.Session
+		}
+	} else if session == "" {
+		session = getenv(sessionEnv)
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("Either --realm or --host is required")
+	}
+	return host, session, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	assert.Equal(t, "/etc/realms.yml", defaultConfigPath(env(map[string]string{
+		"PEBBLECTL_CONFIG": "/etc/realms.yml",
+		"HOME":             "/home/me",
+	})))
+	assert.Equal(t, "/home/me/.pebblectl.yml", defaultConfigPath(env(map[string]string{
+		"HOME": "/home/me",
+	})))
+	assert.Equal(t, "", defaultConfigPath(env(nil)))
+}
+
+func TestLoadRealmsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pebblectl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "realms.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+acme:
+  host: acme.example.com
+  aliases: [acme.local]
+  session: s3cret
+`), 0644))
+
+	config, err := loadRealmsConfig(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, pc.RealmsConfig{
+		"acme": {
+			Host:    "acme.example.com",
+			Aliases: []string{"acme.local"},
+			Session: "s3cret",
+		},
+	}, config)
+
+	missing := filepath.Join(dir, "missing.yml")
+	config, err = loadRealmsConfig(missing, true)
+	require.NoError(t, err)
+	assert.Empty(t, config)
+	_, err = loadRealmsConfig(missing, false)
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("acme:\n  hostname: x\n"), 0644))
+	_, err = loadRealmsConfig(path, false)
+	assert.Error(t, err)
+}
+
+func TestResolveTarget(t *testing.T) {
+	config := pc.RealmsConfig{
+		"acme": {
+			Host:    "acme.example.com",
+			Aliases: []string{"acme.local"},
+			Session: "from-config",
+		},
+	}
+	noEnv := env(nil)
+	withEnv := env(map[string]string{"PEBBLE_SESSION": "from-env"})
+
+	for _, tc := range []struct {
+		realm, host, session string
+		getenv               func(string) string
+		expectedHost         string
+		expectedSession      string
+	}{
+		{"acme", "", "", noEnv, "acme.example.com", "from-config"},
+		{"acme.local", "", "", noEnv, "acme.example.com", "from-config"},
+		{"acme", "localhost:3000", "", noEnv, "localhost:3000", "from-config"},
+		{"acme", "", "", withEnv, "acme.example.com", "from-env"},
+		{"acme", "", "explicit", withEnv, "acme.example.com", "explicit"},
+		{"", "localhost:3000", "", noEnv, "localhost:3000", ""},
+		{"", "localhost:3000", "", withEnv, "localhost:3000", "from-env"},
+	} {
+		host, session, err := resolveTarget(config, tc.realm, tc.host, tc.session, tc.getenv)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedHost, host)
+		assert.Equal(t, tc.expectedSession, session)
+	}
+
+	_, _, err := resolveTarget(config, "other", "", "", noEnv)
+	assert.IsType(t, &pc.NoRealmConfigError{}, err)
+
+	_, _, err = resolveTarget(config, "", "", "", noEnv)
+	assert.EqualError(t, err, "Either --realm or --host is required")
+}
@@ -0,0 +1,263 @@
+// Command pebblectl performs requests against a pebble, for debugging. For
+// example:
+//
+//	pebblectl --realm apdm --service grove get /posts/post.event:apdm.* -p limit=10
+//	pebblectl --realm apdm --service grove post /posts/post.event:apdm -d @post.json
+//
+// Realms are read from ~/.pebblectl.yml, or the file named by $PEBBLECTL_CONFIG,
+// which maps realm names to their host and session:
+//
+//	apdm:
+//	  host: apdm.example.com
+//	  session: 7c1a...
+//
+// The session may also be given with --session or $PEBBLE_SESSION. JSON
+// responses are pretty-printed to standard output. With -v, request and
+// response headers, and timing, are printed to standard error.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+var methods = map[string]string{
+	"get":    "GET",
+	"post":   "POST",
+	"put":    "PUT",
+	"delete": "DELETE",
+	"head":   "HEAD",
+}
+
+// paramsFlag collects repeated "-p key=value" flags.
+type paramsFlag pc.Params
+
+func (p paramsFlag) String() string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = fmt.Sprintf("%s=%v", k, p[k])
+	}
+	return strings.Join(keys, ",")
+}
+
+func (p paramsFlag) Set(s string) error {
+	i := strings.IndexRune(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	p[s[:i]] = s[i+1:]
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run runs the command with arguments, and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	params := paramsFlag{}
+	flags := flag.NewFlagSet("pebblectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		configPath = flags.String("config", "", "realms config `file`; defaults to $"+configEnv+" or ~/.pebblectl.yml")
+		realm      = flags.String("realm", "", "`name` of the realm to connect to")
+		host       = flags.String("host", "", "`host` to connect to, overriding the realm's")
+		service    = flags.String("service", "", "service `name`, such as grove")
+		apiVersion = flags.Int("api-version", 1, "API `version`")
+		protocol   = flags.String("protocol", "http", "`protocol`, http or https")
+		session    = flags.String("session", "", "checkpoint `session`, overriding $"+sessionEnv+" and the realm's")
+		data       = flags.String("d", "", "request `body`; @file reads it from a file, and - from standard input")
+		verbose    = flags.Bool("v", false, "print request and response headers, and timing, to standard error")
+	)
+	flags.Var(params, "p", "`key=value` parameter; may be repeated")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: pebblectl [flags] get|post|put|delete|head <path> [flags]\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	// Flags may come before and after the command and path
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != 2 {
+		flags.Usage()
+		return 2
+	}
+	method, ok := methods[strings.ToLower(positional[0])]
+	if !ok {
+		fmt.Fprintf(stderr, "pebblectl: unknown command %q\n", positional[0])
+		flags.Usage()
+		return 2
+	}
+	path := positional[1]
+	if *service == "" {
+		fmt.Fprintf(stderr, "pebblectl: --service is required\n")
+		return 2
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintf(stderr, "pebblectl: %s\n", pc.RedactError(err))
+		return 1
+	}
+
+	optional := *configPath == ""
+	if optional {
+		*configPath = defaultConfigPath(getenv)
+	}
+	config, err := loadRealmsConfig(*configPath, optional)
+	if err != nil {
+		return fail(err)
+	}
+	targetHost, targetSession, err := resolveTarget(config, *realm, *host, *session, getenv)
+	if err != nil {
+		return fail(err)
+	}
+
+	var body io.Reader
+	if *data != "" {
+		b, err := readBody(*data, stdin)
+		if err != nil {
+			return fail(err)
+		}
+		body = bytes.NewReader(b)
+	} else if method == "POST" || method == "PUT" {
+		body = bytes.NewReader(nil)
+	}
+
+	opts := pc.Options{
+		ServiceName: *service,
+		APIVersion:  *apiVersion,
+		Host:        targetHost,
+		Protocol:    *protocol,
+		Session:     targetSession,
+	}
+	if *verbose {
+		opts.Middleware = []pc.Middleware{verboseMiddleware(stderr)}
+	}
+	client, err := pc.NewHTTPClient(opts)
+	if err != nil {
+		return fail(err)
+	}
+
+	var result json.RawMessage
+	var out interface{} = &result
+	if method == "HEAD" {
+		out = nil
+	}
+	start := time.Now()
+	err = client.Do(path, &pc.RequestOptions{Params: pc.Params(params)}, method, body, out)
+	if *verbose {
+		fmt.Fprintf(stderr, "* Completed in %s\n", time.Since(start))
+	}
+	if err != nil {
+		var reqErr *pc.RequestError
+		if errors.As(err, &reqErr) && len(reqErr.PartialBody) > 0 {
+			writeBody(stdout, reqErr.PartialBody)
+		}
+		return fail(err)
+	}
+	if len(result) > 0 {
+		writeBody(stdout, result)
+	}
+	return 0
+}
+
+// readBody reads a request body given with -d.
+func readBody(data string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case data == "-":
+		return ioutil.ReadAll(stdin)
+	case strings.HasPrefix(data, "@"):
+		return ioutil.ReadFile(data[1:])
+	}
+	return []byte(data), nil
+}
+
+// writeBody writes a response body, indented if it is JSON.
+func writeBody(w io.Writer, body []byte) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err == nil {
+		body = buf.Bytes()
+	}
+	_, _ = w.Write(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		_, _ = io.WriteString(w, "\n")
+	}
+}
+
+// verboseMiddleware prints each request and response, with their headers, in
+// the style of curl -v. The session is redacted, whether it is sent in a
+// header or as a query parameter.
+func verboseMiddleware(w io.Writer) pc.Middleware {
+	return pc.MiddlewareFunc(func(req *http.Request, next pc.Handler) (*http.Response, error) {
+		fmt.Fprintf(w, "> %s %s\n", req.Method, pc.RedactURL(req.URL))
+		writeHeaders(w, "> ", redactHeaders(req.Header))
+		start := time.Now()
+		resp, err := next(req)
+		if err != nil {
+			fmt.Fprintf(w, "* Failed after %s: %s\n", time.Since(start), pc.RedactError(err))
+			return resp, err
+		}
+		fmt.Fprintf(w, "< %s %s (%s)\n", resp.Proto, resp.Status, time.Since(start))
+		writeHeaders(w, "< ", resp.Header)
+		return resp, nil
+	})
+}
+
+func writeHeaders(w io.Writer, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+// redactHeaders returns a copy of request headers with the session removed
+// from the Cookie and Authorization headers.
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	if _, ok := redacted["Authorization"]; ok {
+		redacted.Set("Authorization", "[REDACTED]")
+	}
+	if cookies, ok := redacted["Cookie"]; ok {
+		for i, cookie := range cookies {
+			parts := strings.Split(cookie, ";")
+			for j, part := range parts {
+				if name := strings.TrimSpace(strings.SplitN(part, "=", 2)[0]); name == "checkpoint.session" {
+					parts[j] = " checkpoint.session=[REDACTED]"
+				}
+			}
+			cookies[i] = strings.TrimSpace(strings.Join(parts, ";"))
+		}
+	}
+	return redacted
+}
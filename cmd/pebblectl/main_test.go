@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	Method  string
+	Path    string
+	Query   url.Values
+	Body    string
+	Session string
+}
+
+func newServer(t *testing.T, status int, body string) (*httptest.Server, *[]recordedRequest) {
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		recorded := recordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.Query(),
+			Body:   string(b),
+		}
+		if cookie, err := req.Cookie("checkpoint.session"); err == nil {
+			recorded.Session = cookie.Value
+		}
+		requests = append(requests, recorded)
+		if body != "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Header().Set("X-Test", "yes")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	return server, &requests
+}
+
+func writeFile(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func runCommand(stdin string, vars map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr, env(vars))
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_get(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"post":{"uid":"post.event:acme$1"}}`)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	code, stdout, stderr := runCommand("", nil,
+		"--host", host, "--service", "grove", "get", "/posts/:uid", "-p", "uid=post.event:acme$1", "-p", "raw=true")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "{\n  \"post\": {\n    \"uid\": \"post.event:acme$1\"\n  }\n}\n", stdout)
+	assert.Empty(t, stderr)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "/api/grove/v1/posts/post.event:acme$1", req.Path)
+	assert.Equal(t, url.Values{"raw": {"true"}}, req.Query)
+}
+
+func TestRun_realmAndSession(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{}`)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pebblectl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	host := strings.TrimPrefix(server.URL, "http://")
+	path := writeFile(t, dir, "acme:\n  host: "+host+"\n  session: from-config\n")
+
+	code, _, stderr := runCommand("", map[string]string{"PEBBLECTL_CONFIG": path},
+		"--realm", "acme", "--service", "grove", "get", "/posts")
+	require.Equal(t, 0, code, stderr)
+
+	code, _, stderr = runCommand("", map[string]string{"PEBBLECTL_CONFIG": path, "PEBBLE_SESSION": "from-env"},
+		"--realm", "acme", "--service", "grove", "get", "/posts")
+	require.Equal(t, 0, code, stderr)
+
+	code, _, stderr = runCommand("", map[string]string{"HOME": dir},
+		"--config", path, "--realm", "acme", "--session", "explicit", "--service", "grove", "get", "/posts")
+	require.Equal(t, 0, code, stderr)
+
+	require.Len(t, *requests, 3)
+	assert.Equal(t, "from-config", (*requests)[0].Session)
+	assert.Equal(t, "from-env", (*requests)[1].Session)
+	assert.Equal(t, "explicit", (*requests)[2].Session)
+
+	code, _, stderr = runCommand("", map[string]string{"PEBBLECTL_CONFIG": path},
+		"--realm", "other", "--service", "grove", "get", "/posts")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `No configuration for realm "other"`)
+}
+
+func TestRun_body(t *testing.T) {
+	server, requests := newServer(t, http.StatusCreated, `{"ok":true}`)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pebblectl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := writeFile(t, dir, `{"from":"file"}`)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, tc := range []struct {
+		method, data, stdin, expected string
+	}{
+		{"post", `{"from":"arg"}`, "", `{"from":"arg"}`},
+		{"put", "@" + file, "", `{"from":"file"}`},
+		{"post", "-", `{"from":"stdin"}`, `{"from":"stdin"}`},
+		{"post", "", "", ""},
+	} {
+		args := []string{"--host", host, "--service", "grove", tc.method, "/posts/1"}
+		if tc.data != "" {
+			args = append(args, "-d", tc.data)
+		}
+		code, stdout, stderr := runCommand(tc.stdin, nil, args...)
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "{\n  \"ok\": true\n}\n", stdout)
+
+		req := (*requests)[len(*requests)-1]
+		assert.Equal(t, strings.ToUpper(tc.method), req.Method)
+		assert.Equal(t, tc.expected, req.Body)
+	}
+}
+
+func TestRun_headAndDelete(t *testing.T) {
+	server, requests := newServer(t, http.StatusNoContent, "")
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, method := range []string{"head", "DELETE"} {
+		code, stdout, stderr := runCommand("", nil, "--host", host, "--service", "grove", method, "/posts/1")
+		require.Equal(t, 0, code, stderr)
+		assert.Empty(t, stdout)
+	}
+	require.Len(t, *requests, 2)
+	assert.Equal(t, "HEAD", (*requests)[0].Method)
+	assert.Equal(t, "DELETE", (*requests)[1].Method)
+}
+
+func TestRun_errorStatus(t *testing.T) {
+	server, _ := newServer(t, http.StatusNotFound, `{"error":"not found"}`)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	code, stdout, stderr := runCommand("", nil, "--host", host, "--service", "grove", "get", "/posts/1")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "{\n  \"error\": \"not found\"\n}\n", stdout)
+	assert.Contains(t, stderr, "failed with status 404")
+}
+
+func TestRun_verbose(t *testing.T) {
+	server, _ := newServer(t, http.StatusOK, `{}`)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	code, _, stderr := runCommand("", nil,
+		"-v", "--host", host, "--service", "grove", "--session", "s3cret", "get", "/posts")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "> GET http://"+host+"/api/grove/v1/posts\n")
+	assert.Contains(t, stderr, "> Cookie: checkpoint.session=[REDACTED]\n")
+	assert.Contains(t, stderr, "< HTTP/1.1 200 OK (")
+	assert.Contains(t, stderr, "< X-Test: yes\n")
+	assert.Contains(t, stderr, "* Completed in ")
+	assert.NotContains(t, stderr, "s3cret")
+
+	code, _, stderr = runCommand("", nil,
+		"-v", "--host", host, "--service", "grove", "get", "/posts", "-p", "session=s3cret")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "> GET http://"+host+"/api/grove/v1/posts?session=REDACTED\n")
+	assert.NotContains(t, stderr, "s3cret")
+}
+
+func TestRun_transportErrorRedacted(t *testing.T) {
+	server, _ := newServer(t, http.StatusOK, `{}`)
+	host := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	code, _, stderr := runCommand("", nil,
+		"-v", "--host", host, "--service", "grove", "get", "/posts", "-p", "session=s3cret")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "* Failed after ")
+	assert.Contains(t, stderr, "session=REDACTED")
+	assert.NotContains(t, stderr, "s3cret")
+}
+
+func TestRun_usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--service", "grove", "get"},
+		{"--service", "grove", "patch", "/posts"},
+		{"get", "/posts", "--host", "localhost"},
+		{"--service", "grove", "get", "/posts", "-p", "novalue"},
+	} {
+		code, _, stderr := runCommand("", nil, args...)
+		assert.Equal(t, 2, code, "%v", args)
+		assert.NotEmpty(t, stderr)
+	}
+
+	code, _, stderr := runCommand("", nil, "--service", "grove", "get", "/posts")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "Either --realm or --host is required")
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Bearer s3cret"},
+		"Cookie":        {"a=1; checkpoint.session=s3cret; b=2"},
+	}
+	redacted := redactHeaders(header)
+	assert.Equal(t, http.Header{
+		"Authorization": {"[REDACTED]"},
+		"Cookie":        {"a=1; checkpoint.session=[REDACTED]; b=2"},
+	}, redacted)
+	assert.Equal(t, "Bearer s3cret", header.Get("Authorization"))
+	assert.Equal(t, "a=1; checkpoint.session=s3cret; b=2", header.Get("Cookie"))
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+type clientView struct {
+	Source      string
+	Package     string
+	Service     string
+	APIVersion  int
+	Interface   string
+	Impl        string
+	Constructor string
+	Register    string
+	PC          string
+	HasBody     bool
+	HasReflect  bool
+	HasStrings  bool
+	Imports     []importSpec
+	Methods     []methodView
+}
+
+type methodView struct {
+	Name     string
+	Doc      []string
+	Method   string
+	Path     string
+	Args     string
+	ArgTypes string
+	Fields   string
+	Returns  string
+	Params   string
+	Options  string
+	HasBody  bool
+	Response string
+	Results  []resultView
+	Vars     string
+
+	usesReflect bool
+	usesStrings bool
+}
+
+type resultView struct {
+	Index   int
+	Type    string
+	IsError bool
+	Nilable bool
+}
+
+var clientTemplate = template.Must(template.New("client").Funcs(template.FuncMap{
+	"base": path.Base,
+}).Parse(`// Code generated by pebblegen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .HasBody}}
+	"bytes"
+	"encoding/json"
+{{- end}}
+{{- if .HasReflect}}
+	"reflect"
+{{- end}}
+{{- if .HasStrings}}
+	"strings"
+{{- end}}
+{{- if or .HasBody .HasReflect .HasStrings}}
+{{end}}
+{{- range .Imports}}
+	{{if ne .Name (base .Path)}}{{.Name}} {{end}}"{{.Path}}"
+{{- end}}
+)
+
+// {{.Interface}} is a client for {{.Service}}.
+type {{.Interface}} interface {
+{{- range $i, $m := .Methods}}
+{{- if $i}}
+{{end}}
+{{- range .Doc}}
+	// {{.}}
+{{- end}}
+	{{.Name}}({{.Args}}) {{.Returns}}
+{{- end}}
+}
+
+type {{.Impl}} struct {
+	c {{.PC}}.Client
+}
+
+// {{.Constructor}} constructs a new {{.Service}} client.
+func {{.Constructor}}(c {{.PC}}.Client) ({{.Interface}}, error) {
+	return &{{.Impl}}{c.WithOptions({{.PC}}.Options{
+		ServiceName: {{printf "%q" .Service}},
+		APIVersion:  {{.APIVersion}},
+	})}, nil
+}
+
+// {{.Register}} registers the {{.Service}} client with a connector, so that it
+// can be obtained with Connect.
+func {{.Register}}(connector *{{.PC}}.Connector) {
+	connector.Register((*{{.Interface}})(nil), func(c {{.PC}}.Client) ({{.PC}}.Service, error) {
+		return {{.Constructor}}(c)
+	})
+}
+{{range .Methods}}
+func (c *{{$.Impl}}) {{.Name}}({{.Args}}) {{.Returns}} {
+{{- if .HasBody}}
+	b, err := json.Marshal(body)
+	if err != nil {
+{{- if .Response}}
+		var zero {{.Response}}
+		return zero, err
+{{- else}}
+		return err
+{{- end}}
+	}
+{{end}}
+{{- if .Params}}
+	{{.Params}}
+{{end}}
+{{- if .Response}}
+	var out {{.Response}}
+	if err := c.c.Do({{printf "%q" .Path}}, {{.Options}}, {{printf "%q" .Method}}, {{if .HasBody}}bytes.NewReader(b){{else}}nil{{end}}, &out); err != nil {
+		var zero {{.Response}}
+		return zero, err
+	}
+	return out, nil
+{{- else}}
+	return c.c.Do({{printf "%q" .Path}}, {{.Options}}, {{printf "%q" .Method}}, {{if .HasBody}}bytes.NewReader(b){{else}}nil{{end}}, nil)
+{{- end}}
+}
+{{end}}`))
+
+var mockTemplate = template.Must(template.New("mock").Parse(`// Code generated by pebblegen from {{.Source}}. DO NOT EDIT.
+
+package mocks
+
+{{range .Imports -}}
+import {{.Name}} "{{.Path}}"
+{{end}}
+// {{.Interface}} is an autogenerated mock type for the {{.Interface}} type
+type {{.Interface}} struct {
+	mock.Mock
+}
+{{range $m := .Methods}}
+// {{.Name}} provides a mock function with given fields: {{.Fields}}
+func (_m *{{$.Interface}}) {{.Name}}({{.Args}}) {{.Returns}} {
+	ret := _m.Called({{.Fields}})
+{{range .Results}}
+	var r{{.Index}} {{.Type}}
+	if rf, ok := ret.Get({{.Index}}).(func({{$m.ArgTypes}}) {{.Type}}); ok {
+		r{{.Index}} = rf({{$m.Fields}})
+	} else {
+{{- if .IsError}}
+		r{{.Index}} = ret.Error({{.Index}})
+{{- else if .Nilable}}
+		if ret.Get({{.Index}}) != nil {
+			r{{.Index}} = ret.Get({{.Index}}).({{.Type}})
+		}
+{{- else}}
+		r{{.Index}} = ret.Get({{.Index}}).({{.Type}})
+{{- end}}
+	}
+{{end}}
+	return {{.Vars}}
+}
+{{end}}`))
+
+// GenerateClient generates the source of a typed client for a spec, including
+// its constructor and connector registration function.
+func GenerateClient(spec *Spec, source string) ([]byte, error) {
+	view, err := newClientView(spec, source, "")
+	if err != nil {
+		return nil, err
+	}
+	return render(clientTemplate, view)
+}
+
+// GenerateMock generates the source of a testify mock of the client interface,
+// for a "mocks" package.
+func GenerateMock(spec *Spec, source string) ([]byte, error) {
+	view, err := newClientView(spec, source, spec.Package)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(view.Methods, func(i, j int) bool {
+		return view.Methods[i].Name < view.Methods[j].Name
+	})
+	view.Imports = append([]importSpec{
+		{Name: spec.Package, Path: spec.ImportPath},
+		{Name: "mock", Path: "github.com/stretchr/testify/mock"},
+	}, view.Imports...)
+	return render(mockTemplate, view)
+}
+
+// newClientView builds the template data for a spec. If qualifier is not
+// empty, the spec's own types are qualified with it, for use from another
+// package. Only imports that are used by some type are included.
+func newClientView(spec *Spec, source, qualifier string) (*clientView, error) {
+	imports, err := spec.imports()
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := spec.Interface
+	if suffix == "Client" {
+		suffix = ""
+	}
+	view := &clientView{
+		Source:      source,
+		Package:     spec.Package,
+		Service:     spec.Service,
+		APIVersion:  spec.APIVersion,
+		Interface:   spec.Interface,
+		Impl:        lowerFirst(spec.Interface),
+		Constructor: "New" + suffix,
+		Register:    "Register" + suffix,
+		PC:          imports[0].Name,
+	}
+
+	used := map[string]bool{}
+	if qualifier == "" {
+		// The client itself always uses pebbleclient
+		used[view.PC] = true
+	}
+	qualify := func(typ string) (string, error) {
+		return qualifyType(typ, qualifier, used)
+	}
+
+	importPaths := map[string]string{}
+	for _, imp := range imports {
+		importPaths[imp.Name] = imp.Path
+	}
+	for _, endpoint := range spec.Endpoints {
+		method, err := newMethodView(endpoint, view.PC, importPaths, qualify)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: %s", endpoint.Name, err)
+		}
+		// The mock doesn't build params
+		view.HasBody = view.HasBody || method.HasBody
+		view.HasReflect = view.HasReflect || (method.usesReflect && qualifier == "")
+		view.HasStrings = view.HasStrings || (method.usesStrings && qualifier == "")
+		view.Methods = append(view.Methods, method)
+	}
+
+	for _, imp := range imports {
+		if used[imp.Name] {
+			view.Imports = append(view.Imports, imp)
+		}
+	}
+	return view, nil
+}
+
+func newMethodView(
+	endpoint Endpoint,
+	pc string,
+	importPaths map[string]string,
+	qualify func(string) (string, error)) (methodView, error) {
+	method := methodView{
+		Name:    endpoint.Name,
+		Method:  endpoint.Method,
+		Path:    endpoint.Path,
+		HasBody: endpoint.Request != "",
+	}
+
+	if endpoint.Doc != "" {
+		method.Doc = strings.Split(strings.TrimSpace(endpoint.Doc), "\n")
+	} else {
+		method.Doc = []string{fmt.Sprintf("%s performs %s %s.", endpoint.Name, endpoint.Method, endpoint.Path)}
+	}
+
+	method.Options, method.Params = "nil", ""
+	var required, optional []Param
+	for _, param := range endpoint.Params {
+		if param.Optional {
+			optional = append(optional, param)
+		} else {
+			required = append(required, param)
+		}
+	}
+	if len(endpoint.Params) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s.Params{\n", pc)
+		for _, param := range required {
+			value, usesStrings := paramValue(param, importPaths)
+			method.usesStrings = method.usesStrings || usesStrings
+			fmt.Fprintf(&b, "%q: %s,\n", param.Name, value)
+		}
+		b.WriteString("}")
+		if len(optional) == 0 {
+			method.Options = fmt.Sprintf("&%s.RequestOptions{\nParams: %s,\n}", pc, b.String())
+		} else {
+			var p strings.Builder
+			fmt.Fprintf(&p, "params := %s\n", b.String())
+			for _, param := range optional {
+				check, usesReflect := nonZeroCheck(param, importPaths)
+				value, usesStrings := paramValue(param, importPaths)
+				method.usesReflect = method.usesReflect || usesReflect
+				method.usesStrings = method.usesStrings || usesStrings
+				fmt.Fprintf(&p, "if %s {\nparams[%q] = %s\n}\n", check, param.Name, value)
+			}
+			method.Params = p.String()
+			method.Options = fmt.Sprintf("&%s.RequestOptions{Params: params}", pc)
+		}
+	}
+
+	var args, argTypes, fields []string
+	addArg := func(name, typ string) error {
+		typ, err := qualify(typ)
+		if err != nil {
+			return err
+		}
+		args = append(args, name+" "+typ)
+		argTypes = append(argTypes, typ)
+		fields = append(fields, name)
+		return nil
+	}
+	for _, param := range endpoint.Params {
+		if err := addArg(param.Arg, param.Type); err != nil {
+			return method, err
+		}
+	}
+	if endpoint.Request != "" {
+		if err := addArg("body", endpoint.Request); err != nil {
+			return method, err
+		}
+	}
+	method.Args = strings.Join(args, ", ")
+	method.ArgTypes = strings.Join(argTypes, ", ")
+	method.Fields = strings.Join(fields, ", ")
+
+	if endpoint.Response != "" {
+		response, err := qualify(endpoint.Response)
+		if err != nil {
+			return method, err
+		}
+		nilable, err := isNilable(response)
+		if err != nil {
+			return method, err
+		}
+		method.Response = response
+		method.Returns = "(" + response + ", error)"
+		method.Results = []resultView{
+			{Index: 0, Type: response, Nilable: nilable},
+			{Index: 1, Type: "error", IsError: true},
+		}
+		method.Vars = "r0, r1"
+	} else {
+		method.Returns = "error"
+		method.Results = []resultView{{Index: 0, Type: "error", IsError: true}}
+		method.Vars = "r0"
+	}
+	return method, nil
+}
+
+// timePackage returns the name the time package is imported as, if a type is
+// time.Time or *time.Time, and whether it is a pointer.
+func timePackage(typ string, importPaths map[string]string) (string, bool, bool) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return "", false, false
+	}
+	star, pointer := expr.(*ast.StarExpr)
+	if pointer {
+		expr = star.X
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok && sel.Sel.Name == "Time" {
+		if x, ok := sel.X.(*ast.Ident); ok && importPaths[x.Name] == "time" {
+			return x.Name, pointer, true
+		}
+	}
+	return "", false, false
+}
+
+// paramValue returns the expression for a parameter's value, and true if it
+// uses the strings package. Times are formatted as RFC 3339, in UTC, rather
+// than with time.Time.String, and string slices are joined with commas rather
+// than formatted as "[a b]".
+func paramValue(param Param, importPaths map[string]string) (string, bool) {
+	if name, _, ok := timePackage(param.Type, importPaths); ok {
+		return fmt.Sprintf("%s.UTC().Format(%s.RFC3339)", param.Arg, name), false
+	}
+	if expr, err := parser.ParseExpr(param.Type); err == nil && isStringSlice(expr) {
+		return fmt.Sprintf("strings.Join(%s, \",\")", param.Arg), true
+	}
+	return param.Arg, false
+}
+
+// nonZeroCheck returns the condition under which an optional parameter is
+// sent, and true if it uses the reflect package, which it does for types whose
+// zero value can't be determined from the spec alone.
+func nonZeroCheck(param Param, importPaths map[string]string) (string, bool) {
+	arg := param.Arg
+	if _, pointer, ok := timePackage(param.Type, importPaths); ok {
+		if pointer {
+			return arg + " != nil", false
+		}
+		return "!" + arg + ".IsZero()", false
+	}
+	expr, err := parser.ParseExpr(param.Type)
+	if err != nil {
+		return "", false
+	}
+	switch e := expr.(type) {
+	case *ast.StarExpr, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return arg + " != nil", false
+	case *ast.MapType:
+		return "len(" + arg + ") > 0", false
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "len(" + arg + ") > 0", false
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "string":
+			return arg + ` != ""`, false
+		case "bool":
+			return arg, false
+		case "error":
+			return arg + " != nil", false
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "byte", "rune":
+			return arg + " != 0", false
+		}
+	}
+	return "!reflect.ValueOf(" + arg + ").IsZero()", true
+}
+
+// qualifyType records the packages a type refers to in used, and, if qualifier
+// is not empty, qualifies exported identifiers which are not already qualified,
+// so that "*Widget" becomes "*widgets.Widget".
+func qualifyType(typ, qualifier string, used map[string]bool) (string, error) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return "", fmt.Errorf("invalid type %q", typ)
+	}
+	expr = rewriteType(expr, qualifier, used)
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func rewriteType(expr ast.Expr, qualifier string, used map[string]bool) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if qualifier != "" && ast.IsExported(e.Name) {
+			used[qualifier] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: e}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			used[x.Name] = true
+		}
+	case *ast.StarExpr:
+		e.X = rewriteType(e.X, qualifier, used)
+	case *ast.ArrayType:
+		e.Elt = rewriteType(e.Elt, qualifier, used)
+	case *ast.MapType:
+		e.Key = rewriteType(e.Key, qualifier, used)
+		e.Value = rewriteType(e.Value, qualifier, used)
+	case *ast.ChanType:
+		e.Value = rewriteType(e.Value, qualifier, used)
+	}
+	return expr
+}
+
+// isNilable returns true if a type's zero value is nil.
+func isNilable(typ string) (bool, error) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return false, fmt.Errorf("invalid type %q", typ)
+	}
+	switch e := expr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return true, nil
+	case *ast.ArrayType:
+		return e.Len == nil, nil
+	case *ast.Ident:
+		return e.Name == "error", nil
+	}
+	return false, nil
+}
+
+func render(tmpl *template.Template, view *clientView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Generated invalid code: %s\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
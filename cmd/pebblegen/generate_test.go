@@ -0,0 +1,218 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func checkGolden(t *testing.T, name string, actual []byte) {
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, actual, 0644))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func loadTestSpec(t *testing.T) *Spec {
+	data, err := ioutil.ReadFile("testdata/widgets.yaml")
+	require.NoError(t, err)
+	spec, err := ParseSpec("widgets.yaml", data)
+	require.NoError(t, err)
+	return spec
+}
+
+func TestGenerateClient(t *testing.T) {
+	src, err := GenerateClient(loadTestSpec(t), "widgets.yaml")
+	require.NoError(t, err)
+	checkGolden(t, "widgets_client.go", src)
+}
+
+func TestGenerateMock(t *testing.T) {
+	src, err := GenerateMock(loadTestSpec(t), "widgets.yaml")
+	require.NoError(t, err)
+	checkGolden(t, "mocks_client.go", src)
+}
+
+// stubImporter imports stub packages from testdata/stubs, and the standard
+// library from source.
+type stubImporter struct {
+	packages map[string]*types.Package
+	std      types.Importer
+}
+
+func (imp *stubImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := imp.packages[path]; ok {
+		return pkg, nil
+	}
+	return imp.std.Import(path)
+}
+
+// typeCheck type-checks a package made of the Go files in a directory, if not
+// empty, and extra sources, and makes it available to later imports.
+func (imp *stubImporter) typeCheck(
+	t *testing.T, fset *token.FileSet, path, dir string, sources map[string][]byte) {
+	var files []*ast.File
+	if dir != "" {
+		names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		for _, name := range names {
+			file, err := parser.ParseFile(fset, name, nil, 0)
+			require.NoError(t, err)
+			files = append(files, file)
+		}
+	}
+	for name, src := range sources {
+		file, err := parser.ParseFile(fset, name, src, 0)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+	config := types.Config{Importer: imp}
+	pkg, err := config.Check(path, fset, files, nil)
+	require.NoError(t, err, "type-checking %s", path)
+	imp.packages[path] = pkg
+}
+
+func TestGenerate_typeChecks(t *testing.T) {
+	spec := loadTestSpec(t)
+	client, err := GenerateClient(spec, "widgets.yaml")
+	require.NoError(t, err)
+	mock, err := GenerateMock(spec, "widgets.yaml")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	imp := &stubImporter{
+		packages: map[string]*types.Package{},
+		std:      importer.ForCompiler(fset, "source", nil),
+	}
+	imp.typeCheck(t, fset, pebbleclientImportPath, "testdata/stubs/pebbleclient", nil)
+	imp.typeCheck(t, fset, "github.com/stretchr/testify/mock", "testdata/stubs/mock", nil)
+	imp.typeCheck(t, fset, spec.ImportPath, "testdata/stubs/widgets", map[string][]byte{
+		"widgets_client.go": client,
+	})
+	imp.typeCheck(t, fset, spec.ImportPath+"/mocks", "", map[string][]byte{
+		"client.go": mock,
+	})
+}
+
+func TestGenerateClient_customInterface(t *testing.T) {
+	spec, err := ParseSpec("spec.json", []byte(`{
+		"package": "things",
+		"import_path": "example.com/things",
+		"service": "things",
+		"interface": "ThingClient",
+		"imports": ["pebble github.com/t11e/go-pebbleclient"],
+		"endpoints": [
+			{"name": "Ping", "method": "HEAD", "path": "/ping"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	src, err := GenerateClient(spec, "spec.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "type ThingClient interface {")
+	assert.Contains(t, string(src), "type thingClient struct {")
+	assert.Contains(t, string(src), "func NewThingClient(c pebble.Client) (ThingClient, error) {")
+	assert.Contains(t, string(src), "func RegisterThingClient(connector *pebble.Connector) {")
+	assert.Contains(t, string(src), `return c.c.Do("/ping", nil, "HEAD", nil, nil)`)
+	assert.NotContains(t, string(src), "encoding/json")
+
+	src, err = GenerateMock(spec, "spec.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "type ThingClient struct {")
+	assert.NotContains(t, string(src), "go-pebbleclient")
+}
+
+func Test_qualifyType(t *testing.T) {
+	for typ, expected := range map[string]string{
+		"int":                      "int",
+		"*Widget":                  "*widgets.Widget",
+		"[]*Widget":                "[]*widgets.Widget",
+		"map[Key][]Widget":         "map[widgets.Key][]widgets.Widget",
+		"[3]time.Time":             "[3]time.Time",
+		"chan pc.UID":              "chan pc.UID",
+		"map[string]interface{}":   "map[string]interface{}",
+		"*other.Widget":            "*other.Widget",
+		"[]map[string]*pc.UIDList": "[]map[string]*pc.UIDList",
+	} {
+		used := map[string]bool{}
+		actual, err := qualifyType(typ, "widgets", used)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	used := map[string]bool{}
+	actual, err := qualifyType("map[time.Duration]*Widget", "", used)
+	require.NoError(t, err)
+	assert.Equal(t, "map[time.Duration]*Widget", actual)
+	assert.Equal(t, map[string]bool{"time": true}, used)
+}
+
+func Test_nonZeroCheck(t *testing.T) {
+	importPaths := map[string]string{"t": "time", "pc": pebbleclientImportPath}
+	for typ, expected := range map[string]string{
+		"t.Time":            "!x.IsZero()",
+		"*t.Time":           "x != nil",
+		"string":            `x != ""`,
+		"bool":              "x",
+		"int64":             "x != 0",
+		"*Widget":           "x != nil",
+		"[]string":          "len(x) > 0",
+		"map[string]string": "len(x) > 0",
+		"[2]int":            "!reflect.ValueOf(x).IsZero()",
+		"pc.UIDList":        "!reflect.ValueOf(x).IsZero()",
+	} {
+		check, usesReflect := nonZeroCheck(Param{Arg: "x", Type: typ}, importPaths)
+		assert.Equal(t, expected, check, typ)
+		assert.Equal(t, strings.HasPrefix(expected, "!reflect"), usesReflect, typ)
+	}
+}
+
+func Test_paramValue(t *testing.T) {
+	importPaths := map[string]string{"t": "time"}
+	for typ, expected := range map[string]string{
+		"t.Time":     "x.UTC().Format(t.RFC3339)",
+		"*t.Time":    "x.UTC().Format(t.RFC3339)",
+		"other.Time": "x",
+		"int":        "x",
+		"[]string":   `strings.Join(x, ",")`,
+	} {
+		value, usesStrings := paramValue(Param{Arg: "x", Type: typ}, importPaths)
+		assert.Equal(t, expected, value, typ)
+		assert.Equal(t, strings.HasPrefix(expected, "strings."), usesStrings, typ)
+	}
+}
+
+func Test_isNilable(t *testing.T) {
+	for typ, expected := range map[string]bool{
+		"int":               false,
+		"Widget":            false,
+		"time.Time":         false,
+		"[3]int":            false,
+		"error":             true,
+		"*Widget":           true,
+		"[]Widget":          true,
+		"map[string]Widget": true,
+		"interface{}":       true,
+		"func()":            true,
+		"chan int":          true,
+	} {
+		actual, err := isNilable(typ)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, typ)
+	}
+}
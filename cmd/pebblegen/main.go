@@ -0,0 +1,115 @@
+// Command pebblegen generates a typed client for a pebble from a YAML or JSON
+// description of its endpoints, along with a function to register it with a
+// connector, and a testify mock. It is meant to be run with go generate:
+//
+//	//go:generate go run github.com/t11e/go-pebbleclient/cmd/pebblegen -spec widgets.yaml
+//
+// A spec looks like this:
+//
+//	package: widgets
+//	import_path: github.com/example/widgets
+//	service: widgets
+//	imports:
+//	  - time
+//	endpoints:
+//	  - name: GetWidget
+//	    method: GET
+//	    path: /widgets/:id
+//	    params:
+//	      - {name: id, type: int}
+//	    response: "*Widget"
+//	  - name: CreateWidget
+//	    method: POST
+//	    path: /widgets
+//	    request: "*Widget"
+//	    response: "*Widget"
+//
+// Types are Go types; unqualified types refer to the generated package. Query
+// parameters may be marked "optional: true", so that they are omitted when zero.
+// time.Time parameters are sent in RFC 3339 format, and []string parameters as a
+// comma-separated list. See Spec for all the fields.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	var (
+		specFile = flag.String("spec", "", "`file` containing the spec, in YAML or JSON")
+		out      = flag.String("out", "", "output `file` for the client; defaults to <spec>_client.go next to the spec")
+		mocks    = flag.String("mocks", "", "output `file` for the mock; defaults to mocks/<interface>.go next to the spec")
+		noMock   = flag.Bool("no-mock", false, "do not generate a mock")
+	)
+	flag.Parse()
+	if *specFile == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*specFile, *out, *mocks, !*noMock); err != nil {
+		fmt.Fprintf(os.Stderr, "pebblegen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specFile, out, mocks string, withMock bool) error {
+	data, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+	spec, err := ParseSpec(specFile, data)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(specFile)
+	source := filepath.Base(specFile)
+	if out == "" {
+		out = filepath.Join(dir, strings.TrimSuffix(source, filepath.Ext(source))+"_client.go")
+	}
+	if mocks == "" {
+		mocks = filepath.Join(dir, "mocks", snakeCase(spec.Interface)+".go")
+	}
+
+	src, err := GenerateClient(spec, source)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		return err
+	}
+
+	if !withMock {
+		return nil
+	}
+	src, err = GenerateMock(spec, source)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(mocks), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(mocks, src, 0644)
+}
+
+// snakeCase converts an identifier such as "WidgetClient" to "widget_client",
+// like mockery does for file names.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Spec describes a pebble service and its endpoints.
+type Spec struct {
+	// Package is the name of the Go package the client is generated into.
+	Package string `json:"package" yaml:"package"`
+
+	// ImportPath is the import path of that package, used by the mock.
+	ImportPath string `json:"import_path" yaml:"import_path"`
+
+	// Service is the pebble's service name, such as "grove".
+	Service string `json:"service" yaml:"service"`
+
+	// APIVersion defaults to 1.
+	APIVersion int `json:"api_version" yaml:"api_version"`
+
+	// Interface is the name of the generated interface. Defaults to "Client".
+	Interface string `json:"interface" yaml:"interface"`
+
+	// Imports lists packages used by parameter, request and response types,
+	// either as an import path, or as a name and an import path separated by a
+	// space.
+	Imports []string `json:"imports" yaml:"imports"`
+
+	Endpoints []Endpoint `json:"endpoints" yaml:"endpoints"`
+}
+
+// Endpoint describes a single endpoint, which becomes a method.
+type Endpoint struct {
+	// Name is the method name, such as "GetWidget".
+	Name string `json:"name" yaml:"name"`
+
+	// Doc is an optional doc comment for the method.
+	Doc string `json:"doc" yaml:"doc"`
+
+	// Method is the HTTP method.
+	Method string `json:"method" yaml:"method"`
+
+	// Path is the path template, such as "/widgets/:id".
+	Path string `json:"path" yaml:"path"`
+
+	// Params are path and query parameters, which become method arguments in
+	// order. Every path parameter must be listed.
+	Params []Param `json:"params" yaml:"params"`
+
+	// Request is the optional Go type of the JSON request body, which becomes
+	// the last method argument, named "body".
+	Request string `json:"request" yaml:"request"`
+
+	// Response is the optional Go type the JSON response is decoded into.
+	Response string `json:"response" yaml:"response"`
+}
+
+// Param describes a path or query parameter.
+type Param struct {
+	// Name is the parameter name, as sent to the pebble.
+	Name string `json:"name" yaml:"name"`
+
+	// Arg is the name of the method argument. Defaults to Name in camel case. It
+	// must not shadow the package, an import, or a name used by the endpoint's
+	// types, so a parameter named "time" needs another argument name.
+	Arg string `json:"arg" yaml:"arg"`
+
+	// Type is the Go type of the argument. A time.Time is sent in RFC 3339
+	// format, in UTC, and a []string as a comma-separated list. Other slices,
+	// arrays and maps are not supported; use a type that implements
+	// fmt.Stringer, such as pc.UIDList, instead.
+	Type string `json:"type" yaml:"type"`
+
+	// Optional query parameters are omitted when the argument is the zero value
+	// of its type.
+	Optional bool `json:"optional" yaml:"optional"`
+}
+
+// importSpec is a parsed entry of Spec.Imports.
+type importSpec struct {
+	Name string
+	Path string
+}
+
+const pebbleclientImportPath = "github.com/t11e/go-pebbleclient"
+
+// reservedArgs are names used by the generated code, including the packages it
+// may import.
+var reservedArgs = map[string]bool{
+	"c": true, "b": true, "out": true, "err": true, "zero": true, "body": true,
+	"ret": true, "rf": true, "ok": true, "r0": true, "r1": true, "_m": true,
+	"params": true, "reflect": true, "bytes": true, "json": true,
+	"strings": true,
+}
+
+// ParseSpec parses a spec. Files ending in ".json" are parsed as JSON, and
+// anything else as YAML.
+func ParseSpec(filename string, data []byte) (*Spec, error) {
+	var spec Spec
+	var err error
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = json.Unmarshal(data, &spec)
+	} else {
+		err = yaml.UnmarshalStrict(data, &spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse %s: %s", filename, err)
+	}
+	if err := spec.normalize(); err != nil {
+		return nil, fmt.Errorf("Invalid spec %s: %s", filename, err)
+	}
+	return &spec, nil
+}
+
+func (spec *Spec) normalize() error {
+	if !token.IsIdentifier(spec.Package) {
+		return fmt.Errorf("invalid package %q", spec.Package)
+	}
+	if spec.ImportPath == "" {
+		return fmt.Errorf("import_path is required")
+	}
+	if spec.Service == "" {
+		return fmt.Errorf("service is required")
+	}
+	if spec.APIVersion == 0 {
+		spec.APIVersion = 1
+	}
+	if spec.Interface == "" {
+		spec.Interface = "Client"
+	}
+	if !isExported(spec.Interface) {
+		return fmt.Errorf("invalid interface %q", spec.Interface)
+	}
+	imports, err := spec.imports()
+	if err != nil {
+		return err
+	}
+
+	// Arguments must not shadow the package, its imports or the client type
+	reserved := map[string]bool{
+		spec.Package:               true,
+		lowerFirst(spec.Interface): true,
+	}
+	for name := range reservedArgs {
+		reserved[name] = true
+	}
+	for _, imp := range imports {
+		reserved[imp.Name] = true
+	}
+
+	names := map[string]bool{}
+	for i := range spec.Endpoints {
+		endpoint := &spec.Endpoints[i]
+		if err := endpoint.normalize(reserved); err != nil {
+			return fmt.Errorf("endpoint %q: %s", endpoint.Name, err)
+		}
+		if names[endpoint.Name] {
+			return fmt.Errorf("endpoint %q is defined more than once", endpoint.Name)
+		}
+		names[endpoint.Name] = true
+	}
+	return nil
+}
+
+// normalize validates the endpoint and fills in defaults. Argument names may
+// not be reserved, nor be any identifier used by the endpoint's types.
+func (endpoint *Endpoint) normalize(reserved map[string]bool) error {
+	if !isExported(endpoint.Name) {
+		return fmt.Errorf("invalid name")
+	}
+	endpoint.Method = strings.ToUpper(endpoint.Method)
+	switch endpoint.Method {
+	case "GET", "DELETE":
+		if endpoint.Request != "" {
+			return fmt.Errorf("%s cannot have a request body", endpoint.Method)
+		}
+	case "HEAD":
+		if endpoint.Request != "" || endpoint.Response != "" {
+			return fmt.Errorf("HEAD cannot have a request or response body")
+		}
+	case "POST", "PUT":
+	default:
+		return fmt.Errorf("invalid method %q", endpoint.Method)
+	}
+	if !strings.HasPrefix(endpoint.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+
+	typeNames := map[string]bool{}
+	for _, typ := range []string{endpoint.Request, endpoint.Response} {
+		if typ == "" {
+			continue
+		}
+		expr, err := parser.ParseExpr(typ)
+		if err != nil {
+			return fmt.Errorf("invalid type %q", typ)
+		}
+		addTypeNames(expr, typeNames)
+	}
+	for i := range endpoint.Params {
+		param := &endpoint.Params[i]
+		if param.Name == "" {
+			return fmt.Errorf("parameter name is required")
+		}
+		expr, err := parser.ParseExpr(param.Type)
+		if err != nil || param.Type == "" {
+			return fmt.Errorf("invalid type %q for parameter %q", param.Type, param.Name)
+		}
+		switch expr.(type) {
+		case *ast.ArrayType, *ast.MapType:
+			if !isStringSlice(expr) {
+				return fmt.Errorf("unsupported type %q for parameter %q", param.Type, param.Name)
+			}
+		}
+		addTypeNames(expr, typeNames)
+	}
+
+	args := map[string]bool{}
+	params := map[string]bool{}
+	for i := range endpoint.Params {
+		param := &endpoint.Params[i]
+		if param.Arg == "" {
+			param.Arg = camelCase(param.Name)
+		}
+		if !token.IsIdentifier(param.Arg) || token.IsKeyword(param.Arg) ||
+			reserved[param.Arg] || typeNames[param.Arg] || args[param.Arg] {
+			return fmt.Errorf("invalid argument name %q for parameter %q", param.Arg, param.Name)
+		}
+		args[param.Arg] = true
+		params[param.Name] = true
+	}
+	for _, part := range strings.Split(endpoint.Path, "/") {
+		if len(part) > 1 && part[0] == ':' {
+			if !params[part[1:]] {
+				return fmt.Errorf("path parameter %q is not declared", part[1:])
+			}
+			for _, param := range endpoint.Params {
+				if param.Name == part[1:] && param.Optional {
+					return fmt.Errorf("path parameter %q cannot be optional", param.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isStringSlice returns true if a type is []string.
+func isStringSlice(expr ast.Expr) bool {
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len == nil {
+		elt, ok := array.Elt.(*ast.Ident)
+		return ok && elt.Name == "string"
+	}
+	return false
+}
+
+// addTypeNames adds the identifiers a type refers to, such as "time" and
+// "Widget" in "map[time.Time]*Widget", to names.
+func addTypeNames(expr ast.Expr, names map[string]bool) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			addTypeNames(n.X, names)
+			return false
+		case *ast.Ident:
+			names[n.Name] = true
+		}
+		return true
+	})
+}
+
+// imports parses Spec.Imports. The pebbleclient package is always available
+// as "pc".
+func (spec *Spec) imports() ([]importSpec, error) {
+	imports := []importSpec{{Name: "pc", Path: pebbleclientImportPath}}
+	for _, s := range spec.Imports {
+		fields := strings.Fields(s)
+		var imp importSpec
+		switch len(fields) {
+		case 1:
+			imp.Path = strings.Trim(fields[0], `"`)
+			imp.Name = path.Base(imp.Path)
+		case 2:
+			imp = importSpec{Name: fields[0], Path: strings.Trim(fields[1], `"`)}
+		default:
+			return nil, fmt.Errorf("invalid import %q", s)
+		}
+		if !token.IsIdentifier(imp.Name) {
+			return nil, fmt.Errorf("invalid import %q", s)
+		}
+		if imp.Path == pebbleclientImportPath {
+			imports[0].Name = imp.Name
+			continue
+		}
+		imports = append(imports, imp)
+	}
+	return imports, nil
+}
+
+// camelCase converts a parameter name such as "created_after" or
+// "occurrence[label]" to camel case.
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper && b.Len() > 0 {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
+
+func isExported(name string) bool {
+	return token.IsIdentifier(name) && ast.IsExported(name)
+}
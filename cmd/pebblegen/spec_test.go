@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec("spec.yml", []byte(`
+package: things
+import_path: example.com/things
+service: things
+endpoints:
+  - name: GetThing
+    method: get
+    path: /things/:id
+    params:
+      - {name: id, type: int}
+      - {name: "occurrence[label]", type: string}
+      - {name: sort, arg: sortBy, type: string}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, spec.APIVersion)
+	assert.Equal(t, "Client", spec.Interface)
+	require.Len(t, spec.Endpoints, 1)
+	assert.Equal(t, "GET", spec.Endpoints[0].Method)
+	assert.Equal(t, []Param{
+		{Name: "id", Arg: "id", Type: "int"},
+		{Name: "occurrence[label]", Arg: "occurrenceLabel", Type: "string"},
+		{Name: "sort", Arg: "sortBy", Type: "string"},
+	}, spec.Endpoints[0].Params)
+}
+
+func TestParseSpec_invalid(t *testing.T) {
+	const header = "package: things\nimport_path: example.com/things\nservice: things\n"
+	for spec, expected := range map[string]string{
+		"package: things\nservice: things\n":                                                                               "import_path is required",
+		"package: 1x\nimport_path: x\nservice: things\n":                                                                   `invalid package "1x"`,
+		header + "interface: client\n":                                                                                     `invalid interface "client"`,
+		header + "imports: ['a b c']\n":                                                                                    `invalid import "a b c"`,
+		header + "endpoints:\n- {name: get, method: GET, path: /x}\n":                                                      `endpoint "get": invalid name`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x}\n- {name: Get, method: GET, path: /y}\n":                `endpoint "Get" is defined more than once`,
+		header + "endpoints:\n- {name: Get, method: PATCH, path: /x}\n":                                                    `endpoint "Get": invalid method "PATCH"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: x}\n":                                                       `endpoint "Get": path must start with /`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, request: T}\n":                                          `endpoint "Get": GET cannot have a request body`,
+		header + "endpoints:\n- {name: Get, method: HEAD, path: /x, response: T}\n":                                        `endpoint "Get": HEAD cannot have a request or response body`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x/:id}\n":                                                  `endpoint "Get": path parameter "id" is not declared`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x/:id, params: [{name: id, type: int, optional: true}]}\n": `endpoint "Get": path parameter "id" cannot be optional`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: id, type: '*'}]}\n":                     `endpoint "Get": invalid type "*" for parameter "id"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: body, type: int}]}\n":                   `endpoint "Get": invalid argument name "body" for parameter "body"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: type, type: int}]}\n":                   `endpoint "Get": invalid argument name "type" for parameter "type"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: json, type: bool}]}\n":                  `endpoint "Get": invalid argument name "json" for parameter "json"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: things, type: int}]}\n":                 `endpoint "Get": invalid argument name "things" for parameter "things"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: client, type: int}]}\n":                 `endpoint "Get": invalid argument name "client" for parameter "client"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: pc, type: int}]}\n":                     `endpoint "Get": invalid argument name "pc" for parameter "pc"`,
+		header + "imports: [time]\nendpoints:\n- {name: Get, method: GET, path: /x, params: [{name: time, type: int}]}\n":  `endpoint "Get": invalid argument name "time" for parameter "time"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: state, type: state}]}\n":                `endpoint "Get": invalid argument name "state" for parameter "state"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: w, type: int}], response: '*w'}\n":      `endpoint "Get": invalid argument name "w" for parameter "w"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: ids, type: '[]int'}]}\n":                `endpoint "Get": unsupported type "[]int" for parameter "ids"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: ids, type: '[2]string'}]}\n":            `endpoint "Get": unsupported type "[2]string" for parameter "ids"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, params: [{name: ids, type: 'map[string]int'}]}\n":       `endpoint "Get": unsupported type "map[string]int" for parameter "ids"`,
+		header + "endpoints:\n- {name: Get, method: GET, path: /x, response: 'map['}\n":                                    `endpoint "Get": invalid type "map["`,
+	} {
+		_, err := ParseSpec("spec.yaml", []byte(spec))
+		assert.EqualError(t, err, "Invalid spec spec.yaml: "+expected)
+	}
+
+	_, err := ParseSpec("spec.yaml", []byte("bogus: true\n"))
+	assert.Error(t, err)
+	_, err = ParseSpec("spec.json", []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestSpec_imports(t *testing.T) {
+	spec := &Spec{Imports: []string{
+		"time",
+		`"net/url"`,
+		`wt "example.com/widgets/types"`,
+		`pebble "github.com/t11e/go-pebbleclient"`,
+	}}
+	imports, err := spec.imports()
+	require.NoError(t, err)
+	assert.Equal(t, []importSpec{
+		{Name: "pebble", Path: pebbleclientImportPath},
+		{Name: "time", Path: "time"},
+		{Name: "url", Path: "net/url"},
+		{Name: "wt", Path: "example.com/widgets/types"},
+	}, imports)
+}
+
+func Test_camelCase(t *testing.T) {
+	assert.Equal(t, "id", camelCase("id"))
+	assert.Equal(t, "createdAfter", camelCase("created_after"))
+	assert.Equal(t, "occurrenceLabel", camelCase("occurrence[label]"))
+	assert.Equal(t, "aB", camelCase("_a-b_"))
+}
+
+func Test_snakeCase(t *testing.T) {
+	assert.Equal(t, "client", snakeCase("Client"))
+	assert.Equal(t, "widget_client", snakeCase("WidgetClient"))
+}
@@ -0,0 +1,20 @@
+// Package mock is a stub of testify's mock package, for type-checking
+// generated mocks in tests.
+package mock
+
+type Arguments []interface{}
+
+func (args Arguments) Get(index int) interface{} {
+	return args[index]
+}
+
+func (args Arguments) Error(index int) error {
+	err, _ := args[index].(error)
+	return err
+}
+
+type Mock struct{}
+
+func (m *Mock) Called(arguments ...interface{}) Arguments {
+	return nil
+}
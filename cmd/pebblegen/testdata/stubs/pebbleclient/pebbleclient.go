@@ -0,0 +1,33 @@
+// Package pebbleclient is a stub of the parts of pebbleclient used by
+// generated code, for type-checking it in tests.
+package pebbleclient
+
+import "io"
+
+type Params map[string]interface{}
+
+type RequestOptions struct {
+	Params Params
+}
+
+type Options struct {
+	ServiceName string
+	APIVersion  int
+}
+
+type Client interface {
+	Do(path string, opts *RequestOptions, method string, body io.Reader, result interface{}) error
+	WithOptions(opts Options) Client
+}
+
+type Service interface{}
+
+type ServiceFactoryFunc func(client Client) (Service, error)
+
+type Connector struct{}
+
+func (connector *Connector) Register(intf interface{}, fn ServiceFactoryFunc) {}
+
+type UID string
+
+type UIDList []UID
@@ -0,0 +1,12 @@
+// Package widgets declares the types used by testdata/widgets.yaml.
+package widgets
+
+type Widget struct {
+	ID int
+}
+
+type Stamp struct {
+	By string
+}
+
+type State string
@@ -0,0 +1,156 @@
+package pebbleclient
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ConcurrencyLimit configures a concurrency limiter.
+type ConcurrencyLimit struct {
+	// MaxInFlight is the maximum number of simultaneous requests. Zero means
+	// no limit.
+	MaxInFlight int
+
+	// QueueTimeout is the maximum amount of time a request may wait for a free
+	// slot. Zero means wait until the request's context is done.
+	QueueTimeout time.Duration
+}
+
+// QueueTimeoutError is returned when a request has waited too long for a free
+// slot in a concurrency limiter.
+type QueueTimeoutError struct {
+	Host        string
+	ServiceName string
+	Timeout     time.Duration
+}
+
+func (err *QueueTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %s waiting to send request to %s on %s",
+		err.Timeout, err.ServiceName, err.Host)
+}
+
+type upstreamSlots struct {
+	slots  chan struct{}
+	queued int32
+}
+
+// ConcurrencyLimiter caps the number of requests in flight to each combination
+// of host and service name, queueing excess requests. A concurrency limiter is
+// safe for concurrent use, and is intended to be shared by many clients
+// through Options.ConcurrencyLimiter.
+type ConcurrencyLimiter struct {
+	defaultLimit ConcurrencyLimit
+	serviceLimit map[string]ConcurrencyLimit
+
+	mu        sync.Mutex
+	upstreams map[upstreamKey]*upstreamSlots
+}
+
+// NewConcurrencyLimiter constructs a new concurrency limiter which applies the
+// given limit to each host and service.
+func NewConcurrencyLimiter(limit ConcurrencyLimit) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		defaultLimit: limit,
+		serviceLimit: map[string]ConcurrencyLimit{},
+		upstreams:    map[upstreamKey]*upstreamSlots{},
+	}
+}
+
+// SetServiceLimit overrides the limit for a single service. The limit still
+// applies separately to each host. Must be called before the limiter is used.
+func (limiter *ConcurrencyLimiter) SetServiceLimit(serviceName string, limit ConcurrencyLimit) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.serviceLimit[serviceName] = limit
+}
+
+// Acquire waits for a free slot for a service on a host. On success, returns a
+// function which must be called to release the slot. Returns a
+// *QueueTimeoutError if the queue timeout is exceeded, or the context's error
+// if the context is done first.
+func (limiter *ConcurrencyLimiter) Acquire(
+	ctx context.Context,
+	host, serviceName string) (func(), error) {
+	limit, upstream := limiter.get(host, serviceName)
+	if upstream == nil {
+		return func() {}, nil
+	}
+
+	release := func() {
+		<-upstream.slots
+	}
+
+	select {
+	case upstream.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	atomic.AddInt32(&upstream.queued, 1)
+	defer atomic.AddInt32(&upstream.queued, -1)
+
+	var timeout <-chan time.Time
+	if limit.QueueTimeout > 0 {
+		timer := time.NewTimer(limit.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case upstream.slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, &QueueTimeoutError{
+			Host:        host,
+			ServiceName: serviceName,
+			Timeout:     limit.QueueTimeout,
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// QueueDepth returns the number of requests currently waiting for a free slot
+// for a service on a host.
+func (limiter *ConcurrencyLimiter) QueueDepth(host, serviceName string) int {
+	if _, upstream := limiter.get(host, serviceName); upstream != nil {
+		return int(atomic.LoadInt32(&upstream.queued))
+	}
+	return 0
+}
+
+// InFlight returns the number of requests currently holding a slot for a
+// service on a host.
+func (limiter *ConcurrencyLimiter) InFlight(host, serviceName string) int {
+	if _, upstream := limiter.get(host, serviceName); upstream != nil {
+		return len(upstream.slots)
+	}
+	return 0
+}
+
+func (limiter *ConcurrencyLimiter) get(host, serviceName string) (ConcurrencyLimit, *upstreamSlots) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	limit, ok := limiter.serviceLimit[serviceName]
+	if !ok {
+		limit = limiter.defaultLimit
+	}
+	if limit.MaxInFlight <= 0 {
+		return limit, nil
+	}
+
+	key := newUpstreamKey(host, serviceName)
+	if upstream, ok := limiter.upstreams[key]; ok {
+		return limit, upstream
+	}
+	upstream := &upstreamSlots{
+		slots: make(chan struct{}, limit.MaxInFlight),
+	}
+	limiter.upstreams[key] = upstream
+	return limit, upstream
+}
@@ -0,0 +1,139 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestConcurrencyLimiter_Acquire(t *testing.T) {
+	limiter := pebbleclient.NewConcurrencyLimiter(pebbleclient.ConcurrencyLimit{
+		MaxInFlight: 2,
+	})
+
+	release1, err := limiter.Acquire(ctx, "example.com", "frobnitz")
+	require.NoError(t, err)
+	release2, err := limiter.Acquire(ctx, "example.com", "frobnitz")
+	require.NoError(t, err)
+	assert.Equal(t, 2, limiter.InFlight("example.com", "frobnitz"))
+
+	// Other upstreams are unaffected
+	releaseOther, err := limiter.Acquire(ctx, "example.com", "grove")
+	require.NoError(t, err)
+	releaseOther()
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := limiter.Acquire(ctx, "example.com", "frobnitz")
+		assert.NoError(t, err)
+		close(acquired)
+		release()
+	}()
+
+	for limiter.QueueDepth("example.com", "frobnitz") != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("Acquired slot while limit was reached")
+	default:
+	}
+
+	release1()
+	<-acquired
+	release2()
+	assert.Equal(t, 0, limiter.QueueDepth("example.com", "frobnitz"))
+	assert.Equal(t, 0, limiter.InFlight("example.com", "frobnitz"))
+}
+
+func TestConcurrencyLimiter_Acquire_queueTimeout(t *testing.T) {
+	limiter := pebbleclient.NewConcurrencyLimiter(pebbleclient.ConcurrencyLimit{
+		MaxInFlight:  1,
+		QueueTimeout: 20 * time.Millisecond,
+	})
+
+	release, err := limiter.Acquire(ctx, "example.com", "frobnitz")
+	require.NoError(t, err)
+	defer release()
+
+	_, err = limiter.Acquire(ctx, "example.com", "frobnitz")
+	require.Error(t, err)
+	timeoutErr, ok := err.(*pebbleclient.QueueTimeoutError)
+	require.True(t, ok)
+	assert.Equal(t, "example.com", timeoutErr.Host)
+	assert.Equal(t, "frobnitz", timeoutErr.ServiceName)
+	assert.Equal(t, 0, limiter.QueueDepth("example.com", "frobnitz"))
+}
+
+func TestConcurrencyLimiter_Acquire_contextDone(t *testing.T) {
+	limiter := pebbleclient.NewConcurrencyLimiter(pebbleclient.ConcurrencyLimit{
+		MaxInFlight: 1,
+	})
+
+	release, err := limiter.Acquire(ctx, "example.com", "frobnitz")
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx, "example.com", "frobnitz")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestConcurrencyLimiter_Acquire_unlimited(t *testing.T) {
+	limiter := pebbleclient.NewConcurrencyLimiter(pebbleclient.ConcurrencyLimit{
+		MaxInFlight: 1,
+	})
+	limiter.SetServiceLimit("grove", pebbleclient.ConcurrencyLimit{})
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.Acquire(ctx, "example.com", "grove")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, limiter.InFlight("example.com", "grove"))
+}
+
+func TestClient_Get_concurrencyLimited(t *testing.T) {
+	limiter := pebbleclient.NewConcurrencyLimiter(pebbleclient.ConcurrencyLimit{
+		MaxInFlight: 2,
+	})
+
+	var inFlight, maxInFlight int32
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		ConcurrencyLimiter: limiter,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.Get("hello", nil, nil))
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, atomic.LoadInt32(&maxInFlight) <= 2)
+	assert.Equal(t, 0, limiter.InFlight(hostFromUrl(server.URL), "frobnitz"))
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
-	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -45,27 +44,24 @@ func (config *RealmConfig) ClientOptions() Options {
 
 type RealmsConfig map[string]*RealmConfig
 
+// FindByHost finds the realm whose host or aliases match a host name. Host names
+// are compared after normalization, so case, trailing dots and default ports
+// are ignored.
 func (c RealmsConfig) FindByHost(host string) *RealmConfig {
+	host = normalizeHost(host, "")
 	for _, config := range c {
 		for _, alias := range config.Aliases {
-			if normalizeHost(alias) == normalizeHost(host) {
+			if normalizeHost(alias, "") == host {
 				return config
 			}
 		}
-		if normalizeHost(config.Host) == normalizeHost(host) {
+		if normalizeHost(config.Host, "") == host {
 			return config
 		}
 	}
 	return nil
 }
 
-func normalizeHost(h string) string {
-	if strings.HasSuffix(h, ":80") {
-		return strings.TrimSuffix(h, ":80")
-	}
-	return h
-}
-
 type Connector struct {
 	realms   RealmsConfig
 	registry *Registry
@@ -73,7 +69,13 @@ type Connector struct {
 }
 
 func NewConnectorFromConfig(config RealmsConfig) (*Connector, error) {
-	client, err := NewHTTPClient(Options{})
+	return NewConnectorWithOptions(config, Options{})
+}
+
+// NewConnectorWithOptions constructs a connector whose clients inherit the given
+// options. Realm settings override the options.
+func NewConnectorWithOptions(config RealmsConfig, opts Options) (*Connector, error) {
+	client, err := NewHTTPClient(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -105,11 +107,13 @@ func (connector *Connector) WithRealm(name string) (*Connector, error) {
 	}, nil
 }
 
-// WithRequest returns a new connector which inherits settings from a request. See
-// HTTPClient.FromHTTPRequest for more information.
+// WithRequest returns a new connector which inherits settings from the realm
+// matching a request's host. The host is inferred the same way as
+// HTTPClient.FromHTTPRequest does, including forwarding headers from trusted
+// proxies.
 func (connector *Connector) WithRequest(req *http.Request) (*Connector, error) {
-	host, ok := hostFromRequest(req)
-	if !ok {
+	host := inferRequestOrigin(req, connector.client.GetOptions().TrustedProxies).host
+	if host == "" {
 		return nil, &NoHostConfigError{host}
 	}
 
@@ -125,6 +129,21 @@ func (connector *Connector) WithRequest(req *http.Request) (*Connector, error) {
 	}, nil
 }
 
+// WithOptions returns a new connector whose client has new default client
+// options. See Client.WithOptions.
+func (connector *Connector) WithOptions(opts Options) *Connector {
+	return &Connector{
+		realms:   connector.realms,
+		registry: connector.registry,
+		client:   connector.client.WithOptions(opts),
+	}
+}
+
+// Client returns the connector's client.
+func (connector *Connector) Client() Client {
+	return connector.client
+}
+
 // Connect finds one or more services. The input arguments must be pointers to
 // variables which have the same interface types as those registered with
 // Register().
@@ -120,3 +120,46 @@ func TestConnector_WithRequest_noConfig(t *testing.T) {
 	}
 	assert.Equal(t, "smurf.com", configErr.Host)
 }
+
+func TestConnector_WithRealm_inheritsOptions(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{RequestsPerSecond: 1})
+	c, err := pebbleclient.NewConnectorWithOptions(realms, pebbleclient.Options{
+		RateLimiter: limiter,
+	})
+	assert.NoError(t, err)
+
+	c.Register((*ServiceAInterface)(nil),
+		pebbleclient.ServiceFactoryFunc(func(client pebbleclient.Client) (pebbleclient.Service, error) {
+			return &ServiceAImpl{client}, nil
+		}))
+
+	c2, err := c.WithRealm("acme_inc")
+	assert.NoError(t, err)
+
+	var actual ServiceAInterface
+	assert.NoError(t, c2.Connect(&actual))
+	svcA, ok := actual.(*ServiceAImpl)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "example.com", svcA.client.GetOptions().Host)
+	assert.True(t, limiter == svcA.client.GetOptions().RateLimiter)
+}
+
+func TestConnector_WithRequest_normalizesHost(t *testing.T) {
+	c, err := pebbleclient.NewConnectorFromConfig(realms)
+	assert.NoError(t, err)
+
+	for _, url := range []string{
+		"http://EXAMPLE.com/",
+		"http://example.com:80/",
+		"https://example.com:443/",
+		"http://example.com./",
+	} {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+
+		_, err = c.WithRequest(req)
+		assert.NoError(t, err, url)
+	}
+}
@@ -16,5 +16,19 @@ type RequestError struct {
 
 func (err *RequestError) Error() string {
 	return fmt.Sprintf("Request to %s [%s] failed with status %d: %s",
-		err.client.options.ServiceName, err.Req.URL, err.Resp.StatusCode, err.Resp.Status)
+		err.client.options.ServiceName, RedactURL(err.Req.URL), err.Resp.StatusCode, err.Resp.Status)
+}
+
+// DecodeError is returned when a response body could not be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (err *DecodeError) Error() string {
+	return err.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (err *DecodeError) Cause() error {
+	return err.Err
 }
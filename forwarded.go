@@ -0,0 +1,181 @@
+package pebbleclient
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges, suitable
+// for Options.TrustedProxies.
+func ParseTrustedProxies(specs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(specs))
+	for _, spec := range specs {
+		if strings.ContainsRune(spec, '/') {
+			_, ipNet, err := net.ParseCIDR(spec)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid trusted proxy %q", spec)
+			}
+			nets = append(nets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(spec)
+		if ip == nil {
+			return nil, errors.Errorf("Invalid trusted proxy %q", spec)
+		}
+		bits := 8 * net.IPv4len
+		if ip.To4() == nil {
+			bits = 8 * net.IPv6len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets, nil
+}
+
+// forwardedElement is a single hop of a forwarded request, as described by
+// either the Forwarded header or the X-Forwarded-* headers.
+type forwardedElement struct {
+	forAddr string
+	host    string
+	proto   string
+}
+
+func isTrustedProxy(ip net.IP, trusted []*net.IPNet) bool {
+	if trusted == nil {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedIP parses the IP from a Forwarded "for" value or an X-Forwarded-For
+// entry, which may be an IPv4 address, a bracketed IPv6 address, either one
+// with a port, or an obfuscated identifier, in which case nil is returned.
+func forwardedIP(value string) net.IP {
+	if value == "" {
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	return net.ParseIP(strings.Trim(value, "[]"))
+}
+
+// parseForwarded parses the values of Forwarded headers, per RFC 7239.
+// Malformed pairs are ignored.
+func parseForwarded(values []string) []forwardedElement {
+	var elements []forwardedElement
+	for _, value := range values {
+		for _, elementStr := range splitQuoted(value, ',') {
+			var element forwardedElement
+			for _, pair := range splitQuoted(elementStr, ';') {
+				i := strings.IndexByte(pair, '=')
+				if i == -1 {
+					continue
+				}
+				key := strings.ToLower(strings.TrimSpace(pair[:i]))
+				val := unquote(strings.TrimSpace(pair[i+1:]))
+				switch key {
+				case "for":
+					element.forAddr = val
+				case "host":
+					element.host = val
+				case "proto":
+					element.proto = val
+				}
+			}
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+// parseXForwarded builds forwarded elements from the X-Forwarded-For,
+// X-Forwarded-Host and X-Forwarded-Proto headers. Since proxies don't always
+// append to all three headers, the lists are aligned from the right, that is,
+// from the nearest proxy.
+func parseXForwarded(header http.Header) []forwardedElement {
+	fors := splitHeaderList(header["X-Forwarded-For"])
+	hosts := splitHeaderList(header["X-Forwarded-Host"])
+	protos := splitHeaderList(header["X-Forwarded-Proto"])
+
+	n := len(fors)
+	if len(hosts) > n {
+		n = len(hosts)
+	}
+	if len(protos) > n {
+		n = len(protos)
+	}
+
+	elements := make([]forwardedElement, n)
+	for i := range elements {
+		if j := len(fors) - n + i; j >= 0 {
+			elements[i].forAddr = fors[j]
+		}
+		if j := len(hosts) - n + i; j >= 0 {
+			elements[i].host = hosts[j]
+		}
+		if j := len(protos) - n + i; j >= 0 {
+			elements[i].proto = protos[j]
+		}
+	}
+	return elements
+}
+
+func splitHeaderList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// splitQuoted splits a string on a separator, except where the separator is
+// inside a quoted string.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	inQuotes, escaped := false, false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package pebbleclient
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1", "::1")
+	require.NoError(t, err)
+	require.Len(t, nets, 3)
+	assert.True(t, nets[0].Contains(net.ParseIP("10.1.2.3")))
+	assert.True(t, nets[1].Contains(net.ParseIP("192.168.1.1")))
+	assert.False(t, nets[1].Contains(net.ParseIP("192.168.1.2")))
+	assert.True(t, nets[2].Contains(net.ParseIP("::1")))
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies("smurf")
+	assert.Error(t, err)
+}
+
+func Test_parseForwarded(t *testing.T) {
+	assert.Equal(t, []forwardedElement{
+		{forAddr: "192.0.2.60", host: "example.com", proto: "https"},
+		{forAddr: "[2001:db8:cafe::17]:4711"},
+		{forAddr: "_hidden", host: "a;b,c"},
+	}, parseForwarded([]string{
+		`for=192.0.2.60;proto=https;Host=example.com, For="[2001:db8:cafe::17]:4711"`,
+		`for=_hidden;host="a;b,c";bogus`,
+	}))
+}
+
+func Test_parseXForwarded(t *testing.T) {
+	assert.Equal(t, []forwardedElement{
+		{forAddr: "1.1.1.1"},
+		{forAddr: "2.2.2.2", host: "example.com", proto: "https"},
+		{forAddr: "3.3.3.3", host: "internal.example.com", proto: "http"},
+	}, parseXForwarded(http.Header{
+		"X-Forwarded-For":   {"1.1.1.1, 2.2.2.2", "3.3.3.3"},
+		"X-Forwarded-Host":  {"example.com, internal.example.com"},
+		"X-Forwarded-Proto": {"https,http"},
+	}))
+}
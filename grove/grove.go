@@ -0,0 +1,226 @@
+// Package grove is a client for Grove, the post store.
+package grove
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+//go:generate go run ../vendor/github.com/vektra/mockery/cmd/mockery/mockery.go -name=Client -case=underscore
+
+// Post is a Grove post.
+type Post struct {
+	UID        pc.UID     `json:"uid"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	CreatedBy  int        `json:"created_by,omitempty"`
+	ExternalID string     `json:"external_id,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	Published  bool       `json:"published"`
+	Deleted    bool       `json:"deleted,omitempty"`
+	Restricted bool       `json:"restricted,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Paths      []string   `json:"paths,omitempty"`
+
+	// Occurrences maps occurrence labels, such as "start", to times.
+	Occurrences map[string][]time.Time `json:"occurrences,omitempty"`
+
+	// Document is the post's document. Unless fetched in raw mode, the
+	// external document is merged into it.
+	Document map[string]interface{} `json:"document,omitempty"`
+
+	// ExternalDocument is the part of the document owned by an external
+	// system. Only returned in raw mode.
+	ExternalDocument map[string]interface{} `json:"external_document,omitempty"`
+
+	Sensitive map[string]interface{} `json:"sensitive,omitempty"`
+	Protected map[string]interface{} `json:"protected,omitempty"`
+}
+
+type postEnvelope struct {
+	Post *Post `json:"post"`
+}
+
+type postsResponse struct {
+	Posts []postEnvelope `json:"posts"`
+}
+
+// Occurrence filters posts by an occurrence label and, optionally, a time
+// range. Zero times are open-ended.
+type Occurrence struct {
+	Label string
+	From  time.Time
+	To    time.Time
+}
+
+// GetOptions contains options for fetching posts.
+type GetOptions struct {
+	// Raw returns the document and external document separately, instead of
+	// merged.
+	Raw bool
+
+	// Tags only returns posts which have all of these tags.
+	Tags []string
+
+	// Occurrence only returns posts with a matching occurrence.
+	Occurrence *Occurrence
+}
+
+// Params returns the options as request parameters.
+func (opts *GetOptions) Params() pc.Params {
+	params := pc.Params{}
+	if opts == nil {
+		return params
+	}
+	if opts.Raw {
+		params["raw"] = true
+	}
+	if len(opts.Tags) > 0 {
+		params["tags"] = strings.Join(opts.Tags, ",")
+	}
+	if occ := opts.Occurrence; occ != nil {
+		params["occurrence[label]"] = occ.Label
+		if !occ.From.IsZero() {
+			params["occurrence[from]"] = occ.From.UTC().Format(time.RFC3339)
+		}
+		if !occ.To.IsZero() {
+			params["occurrence[to]"] = occ.To.UTC().Format(time.RFC3339)
+		}
+	}
+	return params
+}
+
+// Client is a client for Grove.
+type Client interface {
+	// GetPost returns a single post by UID.
+	GetPost(uid pc.UID, opts *GetOptions) (*Post, error)
+
+	// GetPosts returns the posts matching a query, which is either a list of
+	// UIDs separated by commas, or a wildcard UID query. The string forms of
+	// pc.UIDList and pc.UIDQuery are valid queries.
+	GetPosts(query string, opts *GetOptions) ([]*Post, error)
+
+	// Query returns a page of posts matching a query. The query is validated
+	// before any request is made. Use Query.Next to fetch the next page.
+	Query(q *Query) (*PostsResult, error)
+
+	// CreatePost creates a post. Its UID must have a class and path, and no
+	// NUID. Returns the created post.
+	CreatePost(post *Post) (*Post, error)
+
+	// UpdatePost updates a post, identified by its UID. Returns the updated post.
+	UpdatePost(post *Post) (*Post, error)
+
+	// DeletePost deletes a post by UID.
+	DeletePost(uid pc.UID) error
+}
+
+type client struct {
+	c pc.Client
+}
+
+// New constructs a new Grove client.
+func New(c pc.Client) (Client, error) {
+	return &client{c.WithOptions(pc.Options{
+		ServiceName: "grove",
+		APIVersion:  1,
+	})}, nil
+}
+
+// Register registers the Grove client with a connector, so that it can be
+// obtained with Connect.
+func Register(connector *pc.Connector) {
+	connector.Register((*Client)(nil), func(c pc.Client) (pc.Service, error) {
+		return New(c)
+	})
+}
+
+func (c *client) GetPost(uid pc.UID, opts *GetOptions) (*Post, error) {
+	params := opts.Params()
+	params["uid"] = uid
+
+	var out postEnvelope
+	if err := c.c.Get("/posts/:uid", &pc.RequestOptions{Params: params}, &out); err != nil {
+		return nil, err
+	}
+	if out.Post == nil {
+		return nil, fmt.Errorf("No post in response for UID %s", uid)
+	}
+	return out.Post, nil
+}
+
+func (c *client) GetPosts(query string, opts *GetOptions) ([]*Post, error) {
+	params := opts.Params()
+	params["uids"] = query
+
+	var out postsResponse
+	if err := c.c.Get("/posts/:uids", &pc.RequestOptions{Params: params}, &out); err != nil {
+		return nil, err
+	}
+	posts := make([]*Post, 0, len(out.Posts))
+	for _, envelope := range out.Posts {
+		if envelope.Post != nil {
+			posts = append(posts, envelope.Post)
+		}
+	}
+	return posts, nil
+}
+
+func (c *client) Query(q *Query) (*PostsResult, error) {
+	params, err := q.Params()
+	if err != nil {
+		return nil, err
+	}
+
+	var out queryResponse
+	if err := c.c.Get("/posts/:uids", &pc.RequestOptions{Params: params}, &out); err != nil {
+		return nil, err
+	}
+	result := &PostsResult{
+		Posts:      make([]*Post, 0, len(out.Posts)),
+		Pagination: out.Pagination,
+	}
+	for _, envelope := range out.Posts {
+		if envelope.Post != nil {
+			result.Posts = append(result.Posts, envelope.Post)
+		}
+	}
+	return result, nil
+}
+
+func (c *client) CreatePost(post *Post) (*Post, error) {
+	return c.write("POST", post)
+}
+
+func (c *client) UpdatePost(post *Post) (*Post, error) {
+	return c.write("PUT", post)
+}
+
+func (c *client) write(method string, post *Post) (*Post, error) {
+	b, err := json.Marshal(postEnvelope{post})
+	if err != nil {
+		return nil, err
+	}
+
+	var out postEnvelope
+	if err := c.c.Do("/posts/:uid", &pc.RequestOptions{
+		Params: pc.Params{"uid": post.UID},
+	}, method, bytes.NewReader(b), &out); err != nil {
+		return nil, err
+	}
+	if out.Post == nil {
+		return nil, fmt.Errorf("No post in response for UID %s", post.UID)
+	}
+	return out.Post, nil
+}
+
+func (c *client) DeletePost(uid pc.UID) error {
+	return c.c.Delete("/posts/:uid", &pc.RequestOptions{
+		Params: pc.Params{"uid": uid},
+	}, nil)
+}
@@ -0,0 +1,168 @@
+package grove_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/grove"
+)
+
+func newClientAndServer(t *testing.T, handler http.HandlerFunc) (grove.Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client, err := pc.NewHTTPClient(pc.Options{
+		Host: u.Host,
+	})
+	require.NoError(t, err)
+
+	g, err := grove.New(client)
+	require.NoError(t, err)
+	return g, server
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestClient_GetPost(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, "/api/grove/v1/posts/post.event:acme.blog$1", req.URL.Path)
+		assert.Equal(t, url.Values{
+			"raw":               {"true"},
+			"tags":              {"a,b"},
+			"occurrence[label]": {"start"},
+			"occurrence[from]":  {"2017-01-01T00:00:00Z"},
+		}, req.URL.Query())
+		writeJSON(w, map[string]interface{}{
+			"post": map[string]interface{}{
+				"uid":               "post.event:acme.blog$1",
+				"published":         true,
+				"tags":              []string{"a", "b"},
+				"document":          map[string]interface{}{"title": "Hello"},
+				"external_document": map[string]interface{}{"source": "feed"},
+				"occurrences": map[string]interface{}{
+					"start": []string{"2017-02-01T10:00:00Z"},
+				},
+			},
+		})
+	})
+	defer server.Close()
+
+	post, err := client.GetPost("post.event:acme.blog$1", &grove.GetOptions{
+		Raw:  true,
+		Tags: []string{"a", "b"},
+		Occurrence: &grove.Occurrence{
+			Label: "start",
+			From:  time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pc.UID("post.event:acme.blog$1"), post.UID)
+	assert.True(t, post.Published)
+	assert.Equal(t, []string{"a", "b"}, post.Tags)
+	assert.Equal(t, "Hello", post.Document["title"])
+	assert.Equal(t, "feed", post.ExternalDocument["source"])
+	assert.Equal(t, []time.Time{time.Date(2017, 2, 1, 10, 0, 0, 0, time.UTC)},
+		post.Occurrences["start"])
+}
+
+func TestClient_GetPost_notFound(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	_, err := client.GetPost("post:acme$1", nil)
+	require.Error(t, err)
+	assert.IsType(t, &pc.RequestError{}, err)
+}
+
+func TestClient_GetPosts(t *testing.T) {
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/grove/v1/posts/post.*:acme.*$*", req.URL.Path)
+		assert.Empty(t, req.URL.RawQuery)
+		writeJSON(w, map[string]interface{}{
+			"posts": []interface{}{
+				map[string]interface{}{"post": map[string]interface{}{"uid": "post:acme$1"}},
+				map[string]interface{}{"post": map[string]interface{}{"uid": "post.event:acme.blog$2"}},
+			},
+		})
+	})
+	defer server.Close()
+
+	posts, err := client.GetPosts(pc.MustParseUIDQuery("post.*:acme.*").String(), nil)
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.Equal(t, pc.UID("post:acme$1"), posts[0].UID)
+	assert.Equal(t, pc.UID("post.event:acme.blog$2"), posts[1].UID)
+}
+
+func TestClient_CreatePost(t *testing.T) {
+	for _, method := range []string{"POST", "PUT"} {
+		client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, method, req.Method)
+			assert.Equal(t, "/api/grove/v1/posts/post.event:acme.blog", req.URL.Path)
+
+			var body map[string]*grove.Post
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			require.NotNil(t, body["post"])
+			assert.Equal(t, "Hello", body["post"].Document["title"])
+
+			body["post"].UID = "post.event:acme.blog$7"
+			writeJSON(w, body)
+		})
+
+		post := &grove.Post{
+			UID:      "post.event:acme.blog",
+			Document: map[string]interface{}{"title": "Hello"},
+		}
+		var err error
+		if method == "POST" {
+			post, err = client.CreatePost(post)
+		} else {
+			post, err = client.UpdatePost(post)
+		}
+		require.NoError(t, err)
+		assert.Equal(t, pc.UID("post.event:acme.blog$7"), post.UID)
+
+		server.Close()
+	}
+}
+
+func TestClient_DeletePost(t *testing.T) {
+	var called bool
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		called = true
+		assert.Equal(t, "DELETE", req.Method)
+		assert.Equal(t, "/api/grove/v1/posts/post:acme$1", req.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	require.NoError(t, client.DeletePost("post:acme$1"))
+	assert.True(t, called)
+}
+
+func TestRegister(t *testing.T) {
+	connector, err := pc.NewConnectorFromConfig(pc.RealmsConfig{
+		"acme": &pc.RealmConfig{Host: "example.com"},
+	})
+	require.NoError(t, err)
+	grove.Register(connector)
+
+	var client grove.Client
+	require.NoError(t, connector.Connect(&client))
+	assert.NotNil(t, client)
+}
@@ -0,0 +1,139 @@
+package mocks
+
+import grove "github.com/t11e/go-pebbleclient/grove"
+import mock "github.com/stretchr/testify/mock"
+import pebbleclient "github.com/t11e/go-pebbleclient"
+
+// Client is an autogenerated mock type for the Client type
+type Client struct {
+	mock.Mock
+}
+
+// CreatePost provides a mock function with given fields: post
+func (_m *Client) CreatePost(post *grove.Post) (*grove.Post, error) {
+	ret := _m.Called(post)
+
+	var r0 *grove.Post
+	if rf, ok := ret.Get(0).(func(*grove.Post) *grove.Post); ok {
+		r0 = rf(post)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grove.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*grove.Post) error); ok {
+		r1 = rf(post)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePost provides a mock function with given fields: uid
+func (_m *Client) DeletePost(uid pebbleclient.UID) error {
+	ret := _m.Called(uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(pebbleclient.UID) error); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPost provides a mock function with given fields: uid, opts
+func (_m *Client) GetPost(uid pebbleclient.UID, opts *grove.GetOptions) (*grove.Post, error) {
+	ret := _m.Called(uid, opts)
+
+	var r0 *grove.Post
+	if rf, ok := ret.Get(0).(func(pebbleclient.UID, *grove.GetOptions) *grove.Post); ok {
+		r0 = rf(uid, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grove.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(pebbleclient.UID, *grove.GetOptions) error); ok {
+		r1 = rf(uid, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPosts provides a mock function with given fields: query, opts
+func (_m *Client) GetPosts(query string, opts *grove.GetOptions) ([]*grove.Post, error) {
+	ret := _m.Called(query, opts)
+
+	var r0 []*grove.Post
+	if rf, ok := ret.Get(0).(func(string, *grove.GetOptions) []*grove.Post); ok {
+		r0 = rf(query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*grove.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *grove.GetOptions) error); ok {
+		r1 = rf(query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Query provides a mock function with given fields: q
+func (_m *Client) Query(q *grove.Query) (*grove.PostsResult, error) {
+	ret := _m.Called(q)
+
+	var r0 *grove.PostsResult
+	if rf, ok := ret.Get(0).(func(*grove.Query) *grove.PostsResult); ok {
+		r0 = rf(q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grove.PostsResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*grove.Query) error); ok {
+		r1 = rf(q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePost provides a mock function with given fields: post
+func (_m *Client) UpdatePost(post *grove.Post) (*grove.Post, error) {
+	ret := _m.Called(post)
+
+	var r0 *grove.Post
+	if rf, ok := ret.Get(0).(func(*grove.Post) *grove.Post); ok {
+		r0 = rf(post)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grove.Post)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*grove.Post) error); ok {
+		r1 = rf(post)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
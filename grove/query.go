@@ -0,0 +1,267 @@
+package grove
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/t11e/go-pebbleclient"
+)
+
+// SortDirection is the direction of a sort order.
+type SortDirection string
+
+const (
+	Ascending  SortDirection = "asc"
+	Descending SortDirection = "desc"
+)
+
+// Visibility controls whether deleted or unpublished posts are returned.
+type Visibility string
+
+const (
+	// Exclude omits the posts. This is the default.
+	Exclude Visibility = ""
+
+	// Include returns the posts along with the others.
+	Include Visibility = "include"
+
+	// Only returns only the posts.
+	Only Visibility = "only"
+)
+
+func (v Visibility) valid() bool {
+	return v == Exclude || v == Include || v == Only
+}
+
+// Pagination describes which page of results was returned.
+type Pagination struct {
+	Limit    int  `json:"limit"`
+	Offset   int  `json:"offset"`
+	LastPage bool `json:"last_page"`
+}
+
+// PostsResult is a page of posts returned by a query.
+type PostsResult struct {
+	Posts      []*Post
+	Pagination Pagination
+}
+
+type queryResponse struct {
+	Posts      []postEnvelope `json:"posts"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+// Query is a Grove post query. It is built fluently, for example:
+//
+//	q := grove.NewQuery("post.event:acme.*").
+//		Tags("music").
+//		CreatedAfter(since).
+//		Sort("created_at", grove.Descending).
+//		Limit(20)
+//
+// Invalid values are not reported until the query is used, by Validate, Params
+// or Client.Query.
+type Query struct {
+	uids          string
+	tags          []string
+	createdAfter  time.Time
+	createdBefore time.Time
+	occurrence    *Occurrence
+	sortBy        string
+	direction     SortDirection
+	deleted       Visibility
+	unpublished   Visibility
+	limit         int
+	hasLimit      bool
+	offset        int
+	raw           bool
+	errs          []string
+}
+
+// NewQuery starts a query for posts matching a list of UIDs separated by
+// commas, or a wildcard UID query.
+func NewQuery(uids string) *Query {
+	return &Query{uids: uids}
+}
+
+// Tags only returns posts which have all of these tags.
+func (q *Query) Tags(tags ...string) *Query {
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsRune(tag, ',') {
+			q.errorf("invalid tag %q", tag)
+		}
+	}
+	q.tags = append(q.tags, tags...)
+	return q
+}
+
+// CreatedAfter only returns posts created after a time.
+func (q *Query) CreatedAfter(t time.Time) *Query {
+	q.createdAfter = t
+	return q
+}
+
+// CreatedBefore only returns posts created before a time.
+func (q *Query) CreatedBefore(t time.Time) *Query {
+	q.createdBefore = t
+	return q
+}
+
+// Occurring only returns posts with an occurrence with a label, such as
+// "start", in a time range. Zero times are open-ended.
+func (q *Query) Occurring(label string, from, to time.Time) *Query {
+	q.occurrence = &Occurrence{Label: label, From: from, To: to}
+	return q
+}
+
+// Sort orders the posts by a field, such as "created_at".
+func (q *Query) Sort(field string, direction SortDirection) *Query {
+	q.sortBy, q.direction = field, direction
+	return q
+}
+
+// Deleted controls whether deleted posts are returned.
+func (q *Query) Deleted(v Visibility) *Query {
+	q.deleted = v
+	return q
+}
+
+// Unpublished controls whether unpublished posts are returned.
+func (q *Query) Unpublished(v Visibility) *Query {
+	q.unpublished = v
+	return q
+}
+
+// Limit sets the maximum number of posts returned.
+func (q *Query) Limit(n int) *Query {
+	q.limit, q.hasLimit = n, true
+	return q
+}
+
+// Offset sets the number of posts to skip.
+func (q *Query) Offset(n int) *Query {
+	q.offset = n
+	return q
+}
+
+// Raw returns the document and external document of each post separately,
+// instead of merged.
+func (q *Query) Raw() *Query {
+	q.raw = true
+	return q
+}
+
+// UIDs returns the UIDs the query matches.
+func (q *Query) UIDs() string {
+	return q.uids
+}
+
+// Next returns a copy of the query for the page following a page of results,
+// or nil if that was the last page. Since the offset could not advance, nil is
+// also returned if the page has no limit.
+func (q *Query) Next(pagination Pagination) *Query {
+	if pagination.LastPage || pagination.Limit <= 0 {
+		return nil
+	}
+	next := *q
+	next.tags = append([]string(nil), q.tags...)
+	next.errs = append([]string(nil), q.errs...)
+	next.limit, next.hasLimit = pagination.Limit, true
+	next.offset = pagination.Offset + pagination.Limit
+	return &next
+}
+
+// Validate returns an error describing everything that is wrong with the query.
+func (q *Query) Validate() error {
+	errs := append([]string(nil), q.errs...)
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if q.uids == "" {
+		add("uids are required")
+	} else {
+		for _, s := range strings.Split(q.uids, ",") {
+			if _, err := pc.ParseUIDQuery(s); err != nil {
+				add("%s", err)
+			}
+		}
+	}
+	if !q.createdAfter.IsZero() && !q.createdBefore.IsZero() &&
+		!q.createdAfter.Before(q.createdBefore) {
+		add("created after must be before created before")
+	}
+	if occ := q.occurrence; occ != nil {
+		if occ.Label == "" {
+			add("occurrence label is required")
+		}
+		if !occ.From.IsZero() && !occ.To.IsZero() && occ.To.Before(occ.From) {
+			add("occurrence range ends before it starts")
+		}
+	}
+	if q.sortBy == "" && q.direction != "" {
+		add("sort direction requires a sort field")
+	}
+	if q.direction != "" && q.direction != Ascending && q.direction != Descending {
+		add("invalid sort direction %q", q.direction)
+	}
+	if !q.deleted.valid() {
+		add("invalid deleted visibility %q", q.deleted)
+	}
+	if !q.unpublished.valid() {
+		add("invalid unpublished visibility %q", q.unpublished)
+	}
+	if q.hasLimit && q.limit < 0 {
+		add("limit must not be negative")
+	}
+	if q.offset < 0 {
+		add("offset must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Invalid Grove query: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Params validates the query, and returns it as request parameters for
+// "/posts/:uids".
+func (q *Query) Params() (pc.Params, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts := &GetOptions{Raw: q.raw, Tags: q.tags, Occurrence: q.occurrence}
+	params := opts.Params()
+	params["uids"] = q.uids
+	if !q.createdAfter.IsZero() {
+		params["created_after"] = q.createdAfter.UTC().Format(time.RFC3339)
+	}
+	if !q.createdBefore.IsZero() {
+		params["created_before"] = q.createdBefore.UTC().Format(time.RFC3339)
+	}
+	if q.sortBy != "" {
+		params["sort_by"] = q.sortBy
+		if q.direction != "" {
+			params["direction"] = string(q.direction)
+		}
+	}
+	if q.deleted != Exclude {
+		params["deleted"] = string(q.deleted)
+	}
+	if q.unpublished != Exclude {
+		params["unpublished"] = string(q.unpublished)
+	}
+	if q.hasLimit {
+		params["limit"] = q.limit
+	}
+	if q.offset > 0 {
+		params["offset"] = q.offset
+	}
+	return params, nil
+}
+
+func (q *Query) errorf(format string, args ...interface{}) {
+	q.errs = append(q.errs, fmt.Sprintf(format, args...))
+}
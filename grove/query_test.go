@@ -0,0 +1,148 @@
+package grove_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pc "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/grove"
+)
+
+func TestQuery_Params(t *testing.T) {
+	params, err := grove.NewQuery("post.event:acme.*").
+		Tags("music", "live").
+		CreatedAfter(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)).
+		CreatedBefore(time.Date(2017, 2, 1, 0, 0, 0, 0, time.UTC)).
+		Occurring("start", time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}).
+		Sort("created_at", grove.Descending).
+		Deleted(grove.Include).
+		Unpublished(grove.Only).
+		Limit(20).
+		Offset(40).
+		Raw().
+		Params()
+	require.NoError(t, err)
+	assert.Equal(t, pc.Params{
+		"uids":              "post.event:acme.*",
+		"tags":              "music,live",
+		"created_after":     "2017-01-01T00:00:00Z",
+		"created_before":    "2017-02-01T00:00:00Z",
+		"occurrence[label]": "start",
+		"occurrence[from]":  "2017-03-01T00:00:00Z",
+		"sort_by":           "created_at",
+		"direction":         "desc",
+		"deleted":           "include",
+		"unpublished":       "only",
+		"limit":             20,
+		"offset":            40,
+		"raw":               true,
+	}, params)
+
+	params, err = grove.NewQuery("post:acme$1,post:acme$2").Params()
+	require.NoError(t, err)
+	assert.Equal(t, pc.Params{"uids": "post:acme$1,post:acme$2"}, params)
+}
+
+func TestQuery_Validate(t *testing.T) {
+	now := time.Now()
+	for _, test := range []struct {
+		query    *grove.Query
+		expected string
+	}{
+		{grove.NewQuery(""), "uids are required"},
+		{grove.NewQuery("post:acme$1,bogus"), "invalid uid query: bogus"},
+		{grove.NewQuery("post:acme").Tags("a,b"), `invalid tag "a,b"`},
+		{grove.NewQuery("post:acme").Tags(""), `invalid tag ""`},
+		{grove.NewQuery("post:acme").CreatedAfter(now).CreatedBefore(now),
+			"created after must be before created before"},
+		{grove.NewQuery("post:acme").Occurring("", time.Time{}, time.Time{}),
+			"occurrence label is required"},
+		{grove.NewQuery("post:acme").Occurring("start", now, now.Add(-time.Hour)),
+			"occurrence range ends before it starts"},
+		{grove.NewQuery("post:acme").Sort("", grove.Ascending),
+			"sort direction requires a sort field"},
+		{grove.NewQuery("post:acme").Sort("created_at", "sideways"),
+			`invalid sort direction "sideways"`},
+		{grove.NewQuery("post:acme").Deleted("maybe"), `invalid deleted visibility "maybe"`},
+		{grove.NewQuery("post:acme").Unpublished("maybe"), `invalid unpublished visibility "maybe"`},
+		{grove.NewQuery("post:acme").Limit(-1), "limit must not be negative"},
+		{grove.NewQuery("post:acme").Offset(-1), "offset must not be negative"},
+		{grove.NewQuery("post:acme").Limit(-1).Offset(-1),
+			"limit must not be negative; offset must not be negative"},
+	} {
+		err := test.query.Validate()
+		assert.EqualError(t, err, "Invalid Grove query: "+test.expected)
+		_, err = test.query.Params()
+		assert.Error(t, err)
+	}
+}
+
+func TestQuery_Next(t *testing.T) {
+	q := grove.NewQuery("post:acme.*").Tags("a").Limit(10)
+	next := q.Next(grove.Pagination{Limit: 10, Offset: 20})
+	require.NotNil(t, next)
+	params, err := next.Params()
+	require.NoError(t, err)
+	assert.Equal(t, 10, params["limit"])
+	assert.Equal(t, 30, params["offset"])
+	assert.Equal(t, "a", params["tags"])
+
+	// The original is unchanged
+	params, err = q.Params()
+	require.NoError(t, err)
+	assert.Nil(t, params["offset"])
+
+	assert.Nil(t, q.Next(grove.Pagination{Limit: 10, Offset: 20, LastPage: true}))
+}
+
+func TestQuery_Next_noLimit(t *testing.T) {
+	q := grove.NewQuery("post:acme.*")
+	assert.Nil(t, q.Next(grove.Pagination{Limit: 0, Offset: 20}))
+	assert.Nil(t, q.Next(grove.Pagination{Limit: -1, Offset: 20}))
+}
+
+func TestClient_Query(t *testing.T) {
+	var requests int
+	client, server := newClientAndServer(t, func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		assert.Equal(t, "/api/grove/v1/posts/post:acme.*", req.URL.Path)
+		query := req.URL.Query()
+		assert.Equal(t, "1", query.Get("limit"))
+		offset := query.Get("offset")
+		uid := "post:acme$1"
+		if offset == "1" {
+			uid = "post:acme$2"
+		}
+		writeJSON(w, map[string]interface{}{
+			"posts": []interface{}{
+				map[string]interface{}{"post": map[string]interface{}{"uid": uid}},
+			},
+			"pagination": map[string]interface{}{
+				"limit":     1,
+				"offset":    map[string]int{"": 0, "1": 1}[offset],
+				"last_page": offset == "1",
+			},
+		})
+	})
+	defer server.Close()
+
+	var uids []pc.UID
+	for q := grove.NewQuery("post:acme.*").Limit(1); q != nil; {
+		result, err := client.Query(q)
+		require.NoError(t, err)
+		for _, post := range result.Posts {
+			uids = append(uids, post.UID)
+		}
+		q = q.Next(result.Pagination)
+	}
+	assert.Equal(t, []pc.UID{"post:acme$1", "post:acme$2"}, uids)
+	assert.Equal(t, 2, requests)
+
+	_, err := client.Query(grove.NewQuery(""))
+	assert.Error(t, err)
+	assert.Equal(t, 2, requests)
+}
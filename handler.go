@@ -0,0 +1,70 @@
+package pebbleclient
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+type connectorContextKey struct{}
+
+// ContextWithConnector returns a new context which carries a connector.
+func ContextWithConnector(ctx context.Context, connector *Connector) context.Context {
+	return context.WithValue(ctx, connectorContextKey{}, connector)
+}
+
+// ConnectorFromContext returns the connector carried by a context, if any.
+func ConnectorFromContext(ctx context.Context) (*Connector, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	connector, ok := ctx.Value(connectorContextKey{}).(*Connector)
+	return connector, ok && connector != nil
+}
+
+// ClientFromContext returns the client of the connector carried by a context,
+// if any.
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	connector, ok := ConnectorFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return connector.Client(), true
+}
+
+// ConnectorHandlerOptions contains options for Connector.Handler.
+type ConnectorHandlerOptions struct {
+	// UnknownHostStatus is the status code returned when the request's host does
+	// not match any realm. Defaults to 404.
+	UnknownHostStatus int
+
+	// UnknownHost is an optional handler which is called instead of returning
+	// UnknownHostStatus when the request's host does not match any realm.
+	UnknownHost http.Handler
+}
+
+// Handler returns HTTP middleware which resolves the realm of each incoming
+// request, and stores a request-scoped connector in the request's context, where
+// it can be retrieved with ConnectorFromContext or ClientFromContext. See
+// WithRequest for how the connector is configured. In addition, its client uses
+// the request's context, so that calls are cancelled along with the request.
+func (connector *Connector) Handler(next http.Handler, opts ConnectorHandlerOptions) http.Handler {
+	if opts.UnknownHostStatus == 0 {
+		opts.UnknownHostStatus = http.StatusNotFound
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reqConnector, err := connector.WithRequest(req)
+		if err != nil {
+			if opts.UnknownHost != nil {
+				opts.UnknownHost.ServeHTTP(w, req)
+				return
+			}
+			http.Error(w, err.Error(), opts.UnknownHostStatus)
+			return
+		}
+
+		ctx := req.Context()
+		reqConnector = reqConnector.WithOptions(Options{Ctx: ctx})
+		next.ServeHTTP(w, req.WithContext(ContextWithConnector(ctx, reqConnector)))
+	})
+}
@@ -0,0 +1,84 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestConnector_Handler_success(t *testing.T) {
+	c, err := pebbleclient.NewConnectorFromConfig(realms)
+	require.NoError(t, err)
+
+	c.Register((*ServiceAInterface)(nil),
+		pebbleclient.ServiceFactoryFunc(func(client pebbleclient.Client) (pebbleclient.Service, error) {
+			return &ServiceAImpl{client}, nil
+		}))
+
+	called := false
+	handler := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+
+		connector, ok := pebbleclient.ConnectorFromContext(req.Context())
+		require.True(t, ok)
+
+		var actual ServiceAInterface
+		require.NoError(t, connector.Connect(&actual))
+		svcA, ok := actual.(*ServiceAImpl)
+		require.True(t, ok)
+		assert.Equal(t, "example.com", svcA.client.GetOptions().Host)
+		assert.Equal(t, "42smurf99", svcA.client.GetOptions().Session)
+		id, _ := pebbleclient.RequestIDFromContext(svcA.client.GetOptions().Ctx)
+		assert.Equal(t, "abc123", id)
+
+		client, ok := pebbleclient.ClientFromContext(req.Context())
+		require.True(t, ok)
+		assert.Equal(t, "example.com", client.GetOptions().Host)
+	}), pebbleclient.ConnectorHandlerOptions{})
+
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	req = req.WithContext(pebbleclient.ContextWithRequestID(req.Context(), "abc123"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestConnector_Handler_unknownHost(t *testing.T) {
+	c, err := pebbleclient.NewConnectorFromConfig(realms)
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("Handler should not be called")
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler(next, pebbleclient.ConnectorHandlerOptions{}).
+		ServeHTTP(w, httptest.NewRequest("GET", "http://smurf.com/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	c.Handler(next, pebbleclient.ConnectorHandlerOptions{
+		UnknownHostStatus: http.StatusMisdirectedRequest,
+	}).ServeHTTP(w, httptest.NewRequest("GET", "http://smurf.com/", nil))
+	assert.Equal(t, http.StatusMisdirectedRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	c.Handler(next, pebbleclient.ConnectorHandlerOptions{
+		UnknownHost: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	}).ServeHTTP(w, httptest.NewRequest("GET", "http://smurf.com/", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestConnectorFromContext_missing(t *testing.T) {
+	_, ok := pebbleclient.ConnectorFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = pebbleclient.ClientFromContext(ctx)
+	assert.False(t, ok)
+}
@@ -0,0 +1,160 @@
+package pebbleclient
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// HedgingPolicy enables request hedging for GET requests: if no response has
+// been received after a delay, an identical request is sent, and whichever
+// response arrives first is used. The other request is cancelled.
+//
+// To keep hedges from adding too much load, the number of hedged requests is
+// capped to a percentage of all requests made through the policy. A policy is
+// safe for concurrent use, and is intended to be shared by many clients
+// through Options.Hedging.
+type HedgingPolicy struct {
+	delay      time.Duration
+	maxPercent float64
+
+	mu       sync.Mutex
+	requests int64
+	hedges   int64
+}
+
+// NewHedgingPolicy constructs a new hedging policy. The delay is how long to
+// wait for a response before hedging. The max percent is the maximum
+// percentage of requests that may be hedged; defaults to 10.
+func NewHedgingPolicy(delay time.Duration, maxPercent float64) *HedgingPolicy {
+	if maxPercent <= 0 {
+		maxPercent = 10
+	}
+	return &HedgingPolicy{
+		delay:      delay,
+		maxPercent: maxPercent,
+	}
+}
+
+// Stats returns the number of requests made through the policy, and how many
+// of them were hedged.
+func (policy *HedgingPolicy) Stats() (requests int64, hedges int64) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	return policy.requests, policy.hedges
+}
+
+func (policy *HedgingPolicy) recordRequest() {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	policy.requests++
+}
+
+func (policy *HedgingPolicy) allowHedge() bool {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if float64(policy.hedges+1) > float64(policy.requests)*policy.maxPercent/100 {
+		return false
+	}
+	policy.hedges++
+	return true
+}
+
+type hedgeResult struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+func (policy *HedgingPolicy) do(
+	ctx context.Context,
+	hc *http.Client,
+	req *http.Request) (*http.Response, error) {
+	policy.recordRequest()
+
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func() {
+		index := len(cancels)
+		reqCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := ctxhttp.Do(reqCtx, hc, req)
+			results <- hedgeResult{index, resp, err, cancel}
+		}()
+	}
+
+	launch()
+	pending := 1
+
+	timer := time.NewTimer(policy.delay)
+	defer timer.Stop()
+	hedgeC := timer.C
+
+	var firstErr error
+	for pending > 0 {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			if policy.allowHedge() {
+				launch()
+				pending++
+			}
+		case result := <-results:
+			pending--
+			if result.err != nil {
+				result.cancel()
+				if firstErr == nil {
+					firstErr = result.err
+				}
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go discardHedgeResults(results, pending)
+			}
+			result.resp.Body = &cancelOnCloseBody{
+				ReadCloser: result.resp.Body,
+				cancel:     result.cancel,
+			}
+			return result.resp, nil
+		}
+	}
+	return nil, firstErr
+}
+
+func discardHedgeResults(results <-chan hedgeResult, count int) {
+	for i := 0; i < count; i++ {
+		result := <-results
+		if result.resp != nil {
+			_, _ = io.Copy(ioutil.Discard, result.resp.Body)
+			_ = result.resp.Body.Close()
+		}
+		result.cancel()
+	}
+}
+
+// cancelOnCloseBody cancels the request's context once the body is closed, so
+// that the winning request of a hedge is not cancelled before its body has
+// been read.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelOnCloseBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
@@ -0,0 +1,116 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestClient_Get_hedged(t *testing.T) {
+	policy := pebbleclient.NewHedgingPolicy(20*time.Millisecond, 100)
+
+	var count int32
+	cancelled := make(chan struct{}, 1)
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Hedging: policy,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			select {
+			case <-req.Context().Done():
+				cancelled <- struct{}{}
+				return
+			case <-time.After(5 * time.Second):
+			}
+			writeJSONDatum(w, http.StatusOK, &Datum{Message: "slow"})
+			return
+		}
+		writeJSONDatum(w, http.StatusOK, &Datum{Message: "fast"})
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	var result *Datum
+	start := time.Now()
+	require.NoError(t, client.Get("hello", nil, &result))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, "fast", result.Message)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Losing request was not cancelled")
+	}
+
+	requests, hedges := policy.Stats()
+	assert.Equal(t, int64(1), requests)
+	assert.Equal(t, int64(1), hedges)
+}
+
+func TestClient_Get_notHedgedWhenFast(t *testing.T) {
+	policy := pebbleclient.NewHedgingPolicy(time.Second, 100)
+
+	var count int32
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Hedging: policy,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&count, 1)
+		writeJSONDatum(w, http.StatusOK, &Datum{Message: "fast"})
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	var result *Datum
+	require.NoError(t, client.Get("hello", nil, &result))
+	assert.Equal(t, "fast", result.Message)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	_, hedges := policy.Stats()
+	assert.Equal(t, int64(0), hedges)
+}
+
+func TestClient_Get_hedgingBudget(t *testing.T) {
+	policy := pebbleclient.NewHedgingPolicy(time.Millisecond, 50)
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Hedging: policy,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, client.Get("hello", nil, nil))
+	}
+
+	requests, hedges := policy.Stats()
+	assert.Equal(t, int64(10), requests)
+	assert.Equal(t, int64(5), hedges)
+}
+
+func TestClient_Post_notHedged(t *testing.T) {
+	policy := pebbleclient.NewHedgingPolicy(time.Millisecond, 100)
+
+	var count int32
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Hedging: policy,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&count, 1)
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Post("hello", nil, nil, nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	requests, _ := policy.Stats()
+	assert.Equal(t, int64(0), requests)
+}
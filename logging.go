@@ -0,0 +1,93 @@
+package pebbleclient
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// StructuredLogger is the interface used for structured logging. It is
+// satisfied by *slog.Logger.
+type StructuredLogger interface {
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+const redacted = "REDACTED"
+
+// redactedQueryParams are query parameters whose values are never logged.
+var redactedQueryParams = []string{"session"}
+
+// logAttempt logs the outcome of a single attempt. Successful attempts are
+// logged at info level, failed attempts at warning level.
+func (client *HTTPClient) logAttempt(
+	ctx context.Context,
+	req *http.Request,
+	attempt int,
+	statusCode int,
+	duration time.Duration,
+	err error) {
+	if client.StructuredLogger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("service", client.ServiceName),
+		slog.String("realm", client.Host),
+		slog.String("method", req.Method),
+		slog.String("url", RedactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if id := req.Header.Get("Request-Id"); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if statusCode != 0 {
+		attrs = append(attrs, slog.Int("status", statusCode))
+	}
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", RedactError(err)))
+	}
+	client.StructuredLogger.LogAttrs(ctx, level, "Pebble request", attrs...)
+}
+
+// RedactURL formats a URL with sensitive query parameters, such as the
+// session, redacted. It is exported for callers that print request URLs, such
+// as in their own logs or debugging output.
+func RedactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for _, key := range redactedQueryParams {
+		if _, ok := query[key]; ok {
+			query.Set(key, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.Redacted()
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return copied.Redacted()
+}
+
+// RedactError formats an error with sensitive query parameters redacted from
+// any URL it carries, such as that of a transport error. It is exported for
+// callers that print errors returned by the client.
+func RedactError(err error) string {
+	msg := err.Error()
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			msg = strings.Replace(msg, urlErr.URL, RedactURL(u), -1)
+		}
+	}
+	return msg
+}
@@ -0,0 +1,81 @@
+package pebbleclient
+
+import (
+	"net"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Error classes reported to metrics collectors.
+const (
+	ErrorClassNone         = ""
+	ErrorClassClient       = "client_error"
+	ErrorClassServer       = "server_error"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassCanceled     = "canceled"
+	ErrorClassQueueTimeout = "queue_timeout"
+	ErrorClassTransport    = "transport"
+	ErrorClassDecode       = "decode"
+	ErrorClassOther        = "other"
+)
+
+// CallMetrics describes a completed call, which may span multiple attempts.
+type CallMetrics struct {
+	ServiceName string
+	Method      string
+
+	// PathTemplate is the path as passed to the client, such as "/posts/:uid",
+	// before parameters are interpolated. Paths without ":param" segments are
+	// truncated to their first segment, such as "/posts/*", since they may
+	// contain values.
+	PathTemplate string
+
+	// StatusCode is the status code of the last response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// Duration is the total time taken, including retries.
+	Duration time.Duration
+
+	// Attempts is the number of attempts made. Retries are Attempts - 1.
+	Attempts int
+
+	// ErrorClass classifies the error, if any. See the ErrorClass constants.
+	ErrorClass string
+}
+
+// MetricsCollector receives metrics about calls made by a client. Collectors
+// must be safe for concurrent use.
+type MetricsCollector interface {
+	ObserveCall(metrics CallMetrics)
+}
+
+// ClassifyError returns the error class of an error returned by a client.
+func ClassifyError(err error) string {
+	switch err := err.(type) {
+	case nil:
+		return ErrorClassNone
+	case *RequestError:
+		if err.Resp.StatusCode >= 500 {
+			return ErrorClassServer
+		}
+		return ErrorClassClient
+	case *QueueTimeoutError:
+		return ErrorClassQueueTimeout
+	case *DecodeError:
+		return ErrorClassDecode
+	case net.Error:
+		if err.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassTransport
+	}
+	switch err {
+	case context.DeadlineExceeded:
+		return ErrorClassTimeout
+	case context.Canceled:
+		return ErrorClassCanceled
+	}
+	return ErrorClassOther
+}
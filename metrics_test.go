@@ -0,0 +1,120 @@
+package pebbleclient_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+type recordingMetrics struct {
+	sync.Mutex
+	calls []pebbleclient.CallMetrics
+}
+
+func (m *recordingMetrics) ObserveCall(call pebbleclient.CallMetrics) {
+	m.Lock()
+	defer m.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func TestClient_Get_metrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+
+	count := 0
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Metrics: metrics,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writeJSONDatum(w, http.StatusOK, &Datum{Message: "hello"})
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	var result *Datum
+	require.NoError(t, client.Get("posts/:uid", &pebbleclient.RequestOptions{
+		Params: pebbleclient.Params{"uid": "post:a.b$1"},
+	}, &result))
+
+	require.Len(t, metrics.calls, 1)
+	call := metrics.calls[0]
+	assert.Equal(t, "frobnitz", call.ServiceName)
+	assert.Equal(t, "GET", call.Method)
+	assert.Equal(t, "/posts/:uid", call.PathTemplate)
+	assert.Equal(t, 200, call.StatusCode)
+	assert.Equal(t, 3, call.Attempts)
+	assert.Equal(t, pebbleclient.ErrorClassNone, call.ErrorClass)
+	assert.True(t, call.Duration > 0)
+}
+
+func TestClient_Get_metricsPathWithoutTemplate(t *testing.T) {
+	metrics := &recordingMetrics{}
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Metrics: metrics,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	for _, path := range []string{"hello", "/posts/post.listing:acme$1", "posts/post:acme$2/children"} {
+		require.NoError(t, client.Get(path, nil, nil))
+	}
+
+	require.Len(t, metrics.calls, 3)
+	assert.Equal(t, "/hello", metrics.calls[0].PathTemplate)
+	assert.Equal(t, "/posts/*", metrics.calls[1].PathTemplate)
+	assert.Equal(t, "/posts/*", metrics.calls[2].PathTemplate)
+}
+
+func TestClient_Get_metricsDecodeError(t *testing.T) {
+	metrics := &recordingMetrics{}
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Metrics: metrics,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{"))
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	var result *Datum
+	err = client.Get("hello", nil, &result)
+	require.Error(t, err)
+	assert.IsType(t, &pebbleclient.DecodeError{}, err)
+
+	require.Len(t, metrics.calls, 1)
+	assert.Equal(t, pebbleclient.ErrorClassDecode, metrics.calls[0].ErrorClass)
+	assert.Equal(t, 200, metrics.calls[0].StatusCode)
+}
+
+func TestClassifyError(t *testing.T) {
+	for idx, test := range []struct {
+		err      error
+		expected string
+	}{
+		{nil, pebbleclient.ErrorClassNone},
+		{&pebbleclient.RequestError{Resp: &http.Response{StatusCode: 404}}, pebbleclient.ErrorClassClient},
+		{&pebbleclient.RequestError{Resp: &http.Response{StatusCode: 503}}, pebbleclient.ErrorClassServer},
+		{&pebbleclient.QueueTimeoutError{Timeout: time.Second}, pebbleclient.ErrorClassQueueTimeout},
+		{&pebbleclient.DecodeError{Err: errors.New("bad")}, pebbleclient.ErrorClassDecode},
+		{context.DeadlineExceeded, pebbleclient.ErrorClassTimeout},
+		{context.Canceled, pebbleclient.ErrorClassCanceled},
+		{errors.New("other"), pebbleclient.ErrorClassOther},
+	} {
+		assert.Equal(t, test.expected, pebbleclient.ClassifyError(test.err), "test %d", idx)
+	}
+}
@@ -0,0 +1,38 @@
+package pebbleclient
+
+import "net/http"
+
+// Handler sends a request and returns its response.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware intercepts requests between being built and being sent. A
+// middleware may modify the request, inspect or replace the response, or
+// return without calling the next handler at all.
+//
+// The request's context carries the client's context. Since failed requests may
+// be retried, a middleware is invoked once per attempt, each time with a fresh
+// copy of the request. A middleware must return either a response or an error.
+type Middleware interface {
+	Handle(req *http.Request, next Handler) (*http.Response, error)
+}
+
+// MiddlewareFunc adapts a function to the Middleware interface.
+type MiddlewareFunc func(req *http.Request, next Handler) (*http.Response, error)
+
+// Handle implements Middleware.
+func (fn MiddlewareFunc) Handle(req *http.Request, next Handler) (*http.Response, error) {
+	return fn(req, next)
+}
+
+// HeaderMiddleware returns a middleware which sets headers on every request.
+func HeaderMiddleware(header http.Header) Middleware {
+	return MiddlewareFunc(func(req *http.Request, next Handler) (*http.Response, error) {
+		for k, vs := range header {
+			req.Header.Del(k)
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+		return next(req)
+	})
+}
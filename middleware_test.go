@@ -0,0 +1,137 @@
+package pebbleclient_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestClient_Get_middlewareOrder(t *testing.T) {
+	var calls []string
+	newMiddleware := func(name string) pebbleclient.Middleware {
+		return pebbleclient.MiddlewareFunc(func(
+			req *http.Request, next pebbleclient.Handler) (*http.Response, error) {
+			calls = append(calls, name+" before")
+			resp, err := next(req)
+			calls = append(calls, name+" after")
+			return resp, err
+		})
+	}
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Middleware: []pebbleclient.Middleware{
+			newMiddleware("a"),
+			newMiddleware("b"),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls = append(calls, "server")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+	assert.Equal(t, []string{"a before", "b before", "server", "b after", "a after"}, calls)
+}
+
+func TestClient_Get_middlewarePreservedByWithOptions(t *testing.T) {
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Middleware: []pebbleclient.Middleware{
+			pebbleclient.HeaderMiddleware(http.Header{"Authorization": {"Bearer xyzzy"}}),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer xyzzy", req.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	derived := client.WithOptions(pebbleclient.Options{Session: "uio3ui3ui3"})
+	require.NoError(t, derived.Get("hello", nil, nil))
+}
+
+func TestClient_Get_middlewareWithCustomHTTPClient(t *testing.T) {
+	called := false
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		HTTPClient: &http.Client{},
+		Middleware: []pebbleclient.Middleware{
+			pebbleclient.MiddlewareFunc(func(
+				req *http.Request, next pebbleclient.Handler) (*http.Response, error) {
+				called = true
+				return next(req)
+			}),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+	assert.True(t, called)
+}
+
+func TestClient_Get_middlewareShortCircuit(t *testing.T) {
+	faultErr := errors.New("injected fault")
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Middleware: []pebbleclient.Middleware{
+			pebbleclient.MiddlewareFunc(func(
+				req *http.Request, next pebbleclient.Handler) (*http.Response, error) {
+				return nil, faultErr
+			}),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("Request should not reach server")
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	assert.Equal(t, faultErr, client.Get("hello", nil, nil))
+}
+
+func TestClient_Get_middlewareNilResponse(t *testing.T) {
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Middleware: []pebbleclient.Middleware{
+			pebbleclient.MiddlewareFunc(func(
+				req *http.Request, next pebbleclient.Handler) (*http.Response, error) {
+				return nil, nil
+			}),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("Request should not reach server")
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	assert.EqualError(t, client.Get("hello", nil, nil),
+		"Middleware returned neither a response nor an error")
+}
+
+func TestClient_Get_middlewareHeadersNotCarriedOverRetries(t *testing.T) {
+	var received [][]string
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Middleware: []pebbleclient.Middleware{
+			pebbleclient.MiddlewareFunc(func(
+				req *http.Request, next pebbleclient.Handler) (*http.Response, error) {
+				req.Header.Add("X-Attempt", "1")
+				return next(req)
+			}),
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received = append(received, req.Header["X-Attempt"])
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+	assert.Equal(t, [][]string{{"1"}, {"1"}}, received)
+}
@@ -0,0 +1,116 @@
+package pebbleclient
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// requestOrigin describes the host and scheme a request was originally made to,
+// before passing through any proxies.
+type requestOrigin struct {
+	// host is the normalized host; see normalizeHost.
+	host  string
+	proto string
+}
+
+// inferRequestOrigin determines the host and scheme an incoming request was
+// originally made to. This is the single place where requests are inspected for
+// this purpose; both HTTPClient.FromHTTPRequest and Connector.WithRequest use it.
+//
+// Forwarding headers (Forwarded, or X-Forwarded-For, X-Forwarded-Host and
+// X-Forwarded-Proto) are only honoured if the request came from a trusted
+// proxy. Each hop is then walked from the nearest proxy outwards, for as long
+// as hops come from trusted proxies. If trusted is nil, the immediate peer is
+// trusted, but only the nearest hop is used, since the hops before it may have
+// been set by the client.
+func inferRequestOrigin(req *http.Request, trusted []*net.IPNet) requestOrigin {
+	host := req.Host
+	proto := requestScheme(req)
+
+	if isTrustedProxy(remoteIP(req.RemoteAddr), trusted) {
+		var elements []forwardedElement
+		if values, ok := req.Header["Forwarded"]; ok {
+			elements = parseForwarded(values)
+		} else {
+			elements = parseXForwarded(req.Header)
+		}
+
+		for i := len(elements) - 1; i >= 0; i-- {
+			element := elements[i]
+			if element.host != "" {
+				host = element.host
+			}
+			if element.proto != "" {
+				proto = strings.ToLower(element.proto)
+			}
+			if trusted == nil || !isTrustedProxy(forwardedIP(element.forAddr), trusted) {
+				break
+			}
+		}
+	}
+
+	return requestOrigin{
+		host:  normalizeHost(host, proto),
+		proto: proto,
+	}
+}
+
+func requestScheme(req *http.Request) string {
+	if req.URL != nil && req.URL.Scheme != "" {
+		return strings.ToLower(req.URL.Scheme)
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// remoteIP parses the IP from a request's remote address.
+func remoteIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// normalizeHost normalizes a host name, optionally with a port, so that it can
+// be compared to other host names and used in URLs:
+//
+// - The host name is lower-cased, and any trailing dot is removed.
+//
+// - The port is removed if it is the default port for the scheme. If the scheme
+// is empty, both 80 and 443 are considered default ports.
+//
+// - IPv6 literals are enclosed in brackets.
+func normalizeHost(host, scheme string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return ""
+	}
+
+	var port string
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	} else {
+		// No port; may be a bracketed or bare IPv6 literal
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	switch {
+	case port == "":
+	case port == "80" && (scheme == "http" || scheme == ""):
+		port = ""
+	case port == "443" && (scheme == "https" || scheme == ""):
+		port = ""
+	}
+
+	if port != "" {
+		return net.JoinHostPort(host, port)
+	}
+	if strings.ContainsRune(host, ':') {
+		return "[" + host + "]"
+	}
+	return host
+}
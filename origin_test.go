@@ -0,0 +1,166 @@
+package pebbleclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_normalizeHost(t *testing.T) {
+	for _, test := range []struct {
+		host     string
+		scheme   string
+		expected string
+	}{
+		{"", "http", ""},
+		{"example.com", "http", "example.com"},
+		{"Example.COM", "http", "example.com"},
+		{"example.com.", "http", "example.com"},
+		{"example.com:80", "http", "example.com"},
+		{"example.com:443", "http", "example.com:443"},
+		{"example.com:443", "https", "example.com"},
+		{"example.com:80", "https", "example.com:80"},
+		{"example.com:80", "", "example.com"},
+		{"example.com:443", "", "example.com"},
+		{"example.com.:8080", "http", "example.com:8080"},
+		{"127.0.0.1:80", "http", "127.0.0.1"},
+		{"[::1]:80", "http", "[::1]"},
+		{"[::1]:8080", "http", "[::1]:8080"},
+		{"[::1]", "http", "[::1]"},
+		{"::1", "http", "[::1]"},
+		{"[2001:DB8::1]:443", "https", "[2001:db8::1]"},
+	} {
+		assert.Equal(t, test.expected, normalizeHost(test.host, test.scheme),
+			"%q with scheme %q", test.host, test.scheme)
+	}
+}
+
+func Test_inferRequestOrigin(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+
+	for idx, test := range []struct {
+		remoteAddr    string
+		header        http.Header
+		tls           bool
+		trusted       []*net.IPNet
+		expectedHost  string
+		expectedProto string
+	}{
+		{
+			remoteAddr:    "1.2.3.4:1234",
+			expectedHost:  "example.com",
+			expectedProto: "http",
+		},
+		{
+			remoteAddr:    "1.2.3.4:1234",
+			tls:           true,
+			expectedHost:  "example.com",
+			expectedProto: "https",
+		},
+		{
+			remoteAddr:    "10.0.0.1:1234",
+			header:        http.Header{"X-Forwarded-Host": {"public.com"}, "X-Forwarded-Proto": {"HTTPS"}},
+			trusted:       trusted,
+			expectedHost:  "public.com",
+			expectedProto: "https",
+		},
+		{
+			// Untrusted peer cannot spoof the host
+			remoteAddr:    "1.2.3.4:1234",
+			header:        http.Header{"X-Forwarded-Host": {"evil.com"}, "X-Forwarded-Proto": {"https"}},
+			trusted:       trusted,
+			expectedHost:  "example.com",
+			expectedProto: "http",
+		},
+		{
+			// Nil trusted list trusts the immediate peer
+			remoteAddr:    "1.2.3.4:1234",
+			header:        http.Header{"X-Forwarded-Host": {"public.com"}},
+			expectedHost:  "public.com",
+			expectedProto: "http",
+		},
+		{
+			// Nil trusted list only uses the nearest hop
+			remoteAddr: "1.2.3.4:1234",
+			header: http.Header{
+				"X-Forwarded-For":  {"6.6.6.6, 10.0.0.2"},
+				"X-Forwarded-Host": {"evil.com, real.com"},
+			},
+			expectedHost:  "real.com",
+			expectedProto: "http",
+		},
+		{
+			// Nil trusted list only uses the nearest Forwarded element
+			remoteAddr: "1.2.3.4:1234",
+			header: http.Header{
+				"Forwarded": {"for=6.6.6.6;host=evil.com;proto=https, for=10.0.0.2;host=real.com"},
+			},
+			expectedHost:  "real.com",
+			expectedProto: "http",
+		},
+		{
+			// Empty trusted list trusts no one
+			remoteAddr:    "10.0.0.1:1234",
+			header:        http.Header{"X-Forwarded-Host": {"public.com"}},
+			trusted:       []*net.IPNet{},
+			expectedHost:  "example.com",
+			expectedProto: "http",
+		},
+		{
+			// Forwarded takes precedence over X-Forwarded-*
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"Forwarded":        {"for=1.2.3.4;host=public.com;proto=https"},
+				"X-Forwarded-Host": {"other.com"},
+			},
+			trusted:       trusted,
+			expectedHost:  "public.com",
+			expectedProto: "https",
+		},
+		{
+			// Chain of trusted proxies is walked until an untrusted hop
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"Forwarded": {
+					"for=1.2.3.4;host=evil.com, for=5.6.7.8;host=public.com;proto=https, " +
+						"for=10.0.0.2;host=lb.internal;proto=http",
+				},
+			},
+			trusted:       trusted,
+			expectedHost:  "public.com",
+			expectedProto: "https",
+		},
+		{
+			// Spoofed leftmost X-Forwarded-Host is not used
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"X-Forwarded-For":  {"6.6.6.6, 1.2.3.4"},
+				"X-Forwarded-Host": {"evil.com, public.com"},
+			},
+			trusted:       trusted,
+			expectedHost:  "public.com",
+			expectedProto: "http",
+		},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = "example.com"
+		req.RemoteAddr = test.remoteAddr
+		if test.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		req.URL.Scheme = ""
+		for k, vs := range test.header {
+			req.Header[k] = vs
+		}
+
+		origin := inferRequestOrigin(req, test.trusted)
+		assert.Equal(t, test.expectedHost, origin.host, "test %d", idx)
+		assert.Equal(t, test.expectedProto, origin.proto, "test %d", idx)
+	}
+}
@@ -0,0 +1,113 @@
+// Package prometheus provides a pebbleclient.MetricsCollector which records
+// call metrics as Prometheus metrics.
+package prometheus
+
+import (
+	"strconv"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+// Options contains options for the collector.
+type Options struct {
+	// Namespace is an optional prefix for metric names. Defaults to "pebble".
+	Namespace string
+
+	// Subsystem is an optional metric name component after the namespace.
+	// Defaults to "client".
+	Subsystem string
+
+	// Buckets are the latency histogram buckets, in seconds. Defaults to
+	// prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// Collector records call metrics. It implements both
+// pebbleclient.MetricsCollector and prometheus.Collector, so it can be passed
+// to the client and registered with a Prometheus registry.
+type Collector struct {
+	requests *prom.CounterVec
+	duration *prom.HistogramVec
+	retries  *prom.CounterVec
+	errors   *prom.CounterVec
+}
+
+var _ pebbleclient.MetricsCollector = (*Collector)(nil)
+var _ prom.Collector = (*Collector)(nil)
+
+// NewCollector constructs a new collector. It must be registered with a
+// registry in order for its metrics to be exported.
+func NewCollector(opts Options) *Collector {
+	if opts.Namespace == "" {
+		opts.Namespace = "pebble"
+	}
+	if opts.Subsystem == "" {
+		opts.Subsystem = "client"
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = prom.DefBuckets
+	}
+
+	labels := []string{"service", "method", "path"}
+	return &Collector{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "requests_total",
+			Help:      "Number of calls made, by status code of the last attempt.",
+		}, append(labels, "code")),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of calls, including retries.",
+			Buckets:   opts.Buckets,
+		}, labels),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "retries_total",
+			Help:      "Number of retried attempts.",
+		}, labels),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "errors_total",
+			Help:      "Number of failed calls, by error class.",
+		}, append(labels, "class")),
+	}
+}
+
+// ObserveCall implements pebbleclient.MetricsCollector.
+func (c *Collector) ObserveCall(m pebbleclient.CallMetrics) {
+	code := ""
+	if m.StatusCode != 0 {
+		code = strconv.Itoa(m.StatusCode)
+	}
+	c.requests.WithLabelValues(m.ServiceName, m.Method, m.PathTemplate, code).Inc()
+	c.duration.WithLabelValues(m.ServiceName, m.Method, m.PathTemplate).Observe(m.Duration.Seconds())
+	if m.Attempts > 1 {
+		c.retries.WithLabelValues(m.ServiceName, m.Method, m.PathTemplate).Add(float64(m.Attempts - 1))
+	}
+	if m.ErrorClass != pebbleclient.ErrorClassNone {
+		c.errors.WithLabelValues(m.ServiceName, m.Method, m.PathTemplate, m.ErrorClass).Inc()
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.retries.Describe(ch)
+	c.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.retries.Collect(ch)
+	c.errors.Collect(ch)
+}
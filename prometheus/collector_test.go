@@ -0,0 +1,64 @@
+package prometheus_test
+
+import (
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pebbleclient "github.com/t11e/go-pebbleclient"
+	"github.com/t11e/go-pebbleclient/prometheus"
+)
+
+func TestCollector_ObserveCall(t *testing.T) {
+	collector := prometheus.NewCollector(prometheus.Options{})
+	registry := prom.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	collector.ObserveCall(pebbleclient.CallMetrics{
+		ServiceName:  "grove",
+		Method:       "GET",
+		PathTemplate: "/posts/:uid",
+		StatusCode:   200,
+		Duration:     50 * time.Millisecond,
+		Attempts:     3,
+	})
+	collector.ObserveCall(pebbleclient.CallMetrics{
+		ServiceName:  "grove",
+		Method:       "GET",
+		PathTemplate: "/posts/:uid",
+		StatusCode:   404,
+		Duration:     10 * time.Millisecond,
+		Attempts:     1,
+		ErrorClass:   pebbleclient.ErrorClassClient,
+	})
+	collector.ObserveCall(pebbleclient.CallMetrics{
+		ServiceName:  "grove",
+		Method:       "GET",
+		PathTemplate: "/posts/:uid",
+		Duration:     time.Second,
+		Attempts:     1,
+		ErrorClass:   pebbleclient.ErrorClassTimeout,
+	})
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := []string{}
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Equal(t, []string{
+		"pebble_client_errors_total",
+		"pebble_client_request_duration_seconds",
+		"pebble_client_requests_total",
+		"pebble_client_retries_total",
+	}, names)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(collector, "pebble_client_requests_total"))
+	assert.Equal(t, 2, testutil.CollectAndCount(collector, "pebble_client_errors_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "pebble_client_retries_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "pebble_client_request_duration_seconds"))
+}
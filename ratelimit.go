@@ -0,0 +1,88 @@
+package pebbleclient
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is a token bucket configuration.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which tokens are added to the bucket.
+	RequestsPerSecond float64
+
+	// Burst is the maximum number of requests that can be made at once. Defaults
+	// to 1.
+	Burst int
+}
+
+// RateLimiter limits the rate of requests made to each combination of host and
+// service name. A rate limiter is safe for concurrent use, and is intended to be
+// shared by many clients through Options.RateLimiter; clients derived through
+// WithOptions, FromHTTPRequest and Connector.WithRealm all share their parent's
+// limiter, and therefore its buckets.
+type RateLimiter struct {
+	defaultLimit RateLimit
+	serviceLimit map[string]RateLimit
+
+	mu       sync.Mutex
+	limiters map[upstreamKey]*rate.Limiter
+}
+
+// NewRateLimiter constructs a new rate limiter which applies the given limit to
+// each host and service.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		defaultLimit: limit,
+		serviceLimit: map[string]RateLimit{},
+		limiters:     map[upstreamKey]*rate.Limiter{},
+	}
+}
+
+// SetServiceLimit overrides the limit for a single service. The limit still
+// applies separately to each host. Must be called before the limiter is used.
+func (limiter *RateLimiter) SetServiceLimit(serviceName string, limit RateLimit) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.serviceLimit[serviceName] = limit
+}
+
+// Wait blocks until a request to a service on a host is permitted, or until the
+// context is done, in which case the context's error is returned.
+func (limiter *RateLimiter) Wait(ctx context.Context, host, serviceName string) error {
+	return limiter.get(host, serviceName).Wait(ctx)
+}
+
+func (limiter *RateLimiter) get(host, serviceName string) *rate.Limiter {
+	key := newUpstreamKey(host, serviceName)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if l, ok := limiter.limiters[key]; ok {
+		return l
+	}
+
+	limit, ok := limiter.serviceLimit[serviceName]
+	if !ok {
+		limit = limiter.defaultLimit
+	}
+	l := rate.NewLimiter(limit.rateLimit(), limit.burst())
+	limiter.limiters[key] = l
+	return l
+}
+
+func (limit RateLimit) rateLimit() rate.Limit {
+	if limit.RequestsPerSecond <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(limit.RequestsPerSecond)
+}
+
+func (limit RateLimit) burst() int {
+	if limit.Burst <= 0 {
+		return 1
+	}
+	return limit.Burst
+}
@@ -0,0 +1,116 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestRateLimiter_Wait_burst(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 10,
+		Burst:             3,
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(ctx, "example.com", "frobnitz"))
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+
+	require.NoError(t, limiter.Wait(ctx, "example.com", "frobnitz"))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestRateLimiter_Wait_separateBuckets(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 1,
+	})
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx, "example.com", "frobnitz"))
+	require.NoError(t, limiter.Wait(ctx, "example.com", "grove"))
+	require.NoError(t, limiter.Wait(ctx, "example.org", "frobnitz"))
+	require.NoError(t, limiter.Wait(ctx, "EXAMPLE.org", "grove"))
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+}
+
+func TestRateLimiter_Wait_serviceLimit(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 1,
+	})
+	limiter.SetServiceLimit("grove", pebbleclient.RateLimit{})
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, limiter.Wait(ctx, "example.com", "grove"))
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond)
+}
+
+func TestRateLimiter_Wait_contextDone(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 0.1,
+	})
+	require.NoError(t, limiter.Wait(ctx, "example.com", "frobnitz"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Error(t, limiter.Wait(ctx, "example.com", "frobnitz"))
+}
+
+func TestClient_Get_rateLimited(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 20,
+	})
+
+	count := 0
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		RateLimiter: limiter,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Derived clients share the parent's buckets
+	derived := client.WithOptions(pebbleclient.Options{Session: "uio3ui3ui3"})
+
+	start := time.Now()
+	require.NoError(t, client.Get("hello", nil, nil))
+	require.NoError(t, derived.Get("hello", nil, nil))
+	require.NoError(t, client.Get("hello", nil, nil))
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+	assert.Equal(t, 3, count)
+}
+
+func TestClient_Get_rateLimitedContextDone(t *testing.T) {
+	limiter := pebbleclient.NewRateLimiter(pebbleclient.RateLimit{
+		RequestsPerSecond: 0.1,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		RateLimiter: limiter,
+		Ctx:         ctx,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+	assert.Error(t, client.Get("hello", nil, nil))
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,70 @@
+package pebbleclient
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// requestIDHeaders are the headers from which request IDs are read, in order
+// of preference.
+var requestIDHeaders = []string{"Request-Id", "X-Request-Id"}
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a new context which carries a request ID. Clients
+// using this context send the request ID, unless one is set in the options.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by a context, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// NewRequestID generates a new random request ID.
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// requestIDFromRequest returns the request ID of an incoming request, looking
+// at the request's context first, then the request headers.
+func requestIDFromRequest(req *http.Request) (string, bool) {
+	if id, ok := RequestIDFromContext(req.Context()); ok {
+		return id, true
+	}
+	for _, header := range requestIDHeaders {
+		if id := req.Header.Get(header); id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// RequestIDHandler is HTTP middleware which ensures every incoming request has a
+// request ID. The ID is taken from the Request-Id or X-Request-Id headers, or
+// generated if missing. It is stored in the request context, so that clients
+// created with FromHTTPRequest, or using the request's context, pass it on, and
+// set as the Request-Id header on both the request and the response.
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, ok := requestIDFromRequest(req)
+		if !ok {
+			id = NewRequestID()
+		}
+		req.Header.Set("Request-Id", id)
+		w.Header().Set("Request-Id", id)
+		next.ServeHTTP(w, req.WithContext(ContextWithRequestID(req.Context(), id)))
+	})
+}
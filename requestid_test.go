@@ -0,0 +1,118 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestClient_Get_requestIDFromOptions(t *testing.T) {
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		RequestID: "abc123",
+		Ctx:       pebbleclient.ContextWithRequestID(context.Background(), "ignored"),
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "abc123", req.Header.Get("Request-Id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+}
+
+func TestClient_Get_requestIDFromContext(t *testing.T) {
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Ctx: pebbleclient.ContextWithRequestID(context.Background(), "abc123"),
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "abc123", req.Header.Get("Request-Id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+}
+
+func TestClient_Get_requestIDGenerated(t *testing.T) {
+	var ids []string
+	client, server, err := newClientAndServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ids = append(ids, req.Header.Get("Request-Id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+	require.NoError(t, client.Get("hello", nil, nil))
+	require.Len(t, ids, 2)
+	assert.Len(t, ids[0], 32)
+	assert.NotEqual(t, ids[0], ids[1])
+}
+
+func TestClient_FromHTTPRequest_requestID(t *testing.T) {
+	for idx, test := range []struct {
+		header   string
+		ctxID    string
+		expected string
+	}{
+		{header: "Request-Id", expected: "abc123"},
+		{header: "X-Request-Id", expected: "abc123"},
+		{header: "X-Request-Id", ctxID: "def456", expected: "def456"},
+	} {
+		req, err := http.NewRequest("GET", "http://example.com/", nil)
+		require.NoError(t, err)
+		req.Header.Set(test.header, "abc123")
+		if test.ctxID != "" {
+			req = req.WithContext(pebbleclient.ContextWithRequestID(req.Context(), test.ctxID))
+		}
+
+		client, err := pebbleclient.NewHTTPClient(pebbleclient.Options{
+			ServiceName: "frobnitz",
+		})
+		require.NoError(t, err)
+
+		client, err = client.FromHTTPRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, client.GetOptions().RequestID, "test %d", idx)
+	}
+}
+
+func TestRequestIDHandler(t *testing.T) {
+	for idx, test := range []struct {
+		header   string
+		value    string
+		expected string
+	}{
+		{header: "Request-Id", value: "abc123", expected: "abc123"},
+		{header: "X-Request-Id", value: "abc123", expected: "abc123"},
+		{},
+	} {
+		var seen string
+		handler := pebbleclient.RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			id, ok := pebbleclient.RequestIDFromContext(req.Context())
+			assert.True(t, ok)
+			assert.Equal(t, id, req.Header.Get("Request-Id"))
+			seen = id
+		}))
+
+		req := httptest.NewRequest("GET", "http://example.com/", nil)
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if test.expected != "" {
+			assert.Equal(t, test.expected, seen, "test %d", idx)
+		} else {
+			assert.Len(t, seen, 32, "test %d", idx)
+		}
+		assert.Equal(t, seen, w.Header().Get("Request-Id"), "test %d", idx)
+	}
+}
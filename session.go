@@ -0,0 +1,100 @@
+package pebbleclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SessionExtractor extracts a Checkpoint session key from an incoming request.
+type SessionExtractor interface {
+	ExtractSession(req *http.Request) (string, bool)
+}
+
+// SessionExtractorFunc adapts a function to the SessionExtractor interface.
+type SessionExtractorFunc func(req *http.Request) (string, bool)
+
+// ExtractSession implements SessionExtractor.
+func (fn SessionExtractorFunc) ExtractSession(req *http.Request) (string, bool) {
+	return fn(req)
+}
+
+// DefaultSessionExtractors are used when Options.SessionExtractors is nil. They
+// look for the "session" query parameter, then the "checkpoint.session" cookie.
+var DefaultSessionExtractors = []SessionExtractor{
+	SessionFromQueryParam("session"),
+	SessionFromCookie("checkpoint.session"),
+}
+
+// SessionFromQueryParam returns an extractor which reads the session from a
+// query parameter.
+func SessionFromQueryParam(name string) SessionExtractor {
+	return SessionExtractorFunc(func(req *http.Request) (string, bool) {
+		session := req.URL.Query().Get(name)
+		return session, session != ""
+	})
+}
+
+// SessionFromCookie returns an extractor which reads the session from a cookie.
+func SessionFromCookie(name string) SessionExtractor {
+	return SessionExtractorFunc(func(req *http.Request) (string, bool) {
+		cookie, err := req.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", false
+		}
+		return cookie.Value, true
+	})
+}
+
+// SessionFromHeader returns an extractor which reads the session from a header,
+// such as X-Session.
+func SessionFromHeader(name string) SessionExtractor {
+	return SessionExtractorFunc(func(req *http.Request) (string, bool) {
+		session := strings.TrimSpace(req.Header.Get(name))
+		return session, session != ""
+	})
+}
+
+// SessionFromBearerToken returns an extractor which reads the session from an
+// "Authorization: Bearer <session>" header.
+func SessionFromBearerToken() SessionExtractor {
+	return SessionExtractorFunc(func(req *http.Request) (string, bool) {
+		auth := req.Header.Get("Authorization")
+		const prefix = "bearer "
+		if len(auth) <= len(prefix) || strings.ToLower(auth[:len(prefix)]) != prefix {
+			return "", false
+		}
+		session := strings.TrimSpace(auth[len(prefix):])
+		return session, session != ""
+	})
+}
+
+// ExtractSession runs extractors in order, returning the first session found. If
+// extractors is nil, DefaultSessionExtractors are used.
+func ExtractSession(req *http.Request, extractors []SessionExtractor) (string, bool) {
+	if extractors == nil {
+		extractors = DefaultSessionExtractors
+	}
+	for _, extractor := range extractors {
+		if session, ok := extractor.ExtractSession(req); ok {
+			return session, true
+		}
+	}
+	return "", false
+}
+
+// setSession adds a session to an outgoing request, either as a cookie or, if
+// a header is given, in that header. The Authorization header gets a bearer
+// token.
+func setSession(req *http.Request, session string, header string) {
+	switch {
+	case header == "":
+		req.AddCookie(&http.Cookie{
+			Name:  "checkpoint.session",
+			Value: session,
+		})
+	case http.CanonicalHeaderKey(header) == "Authorization":
+		req.Header.Set(header, "Bearer "+session)
+	default:
+		req.Header.Set(header, session)
+	}
+}
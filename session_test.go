@@ -0,0 +1,106 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func TestSessionExtractors(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://example.com/?session=fromquery", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "checkpoint.session", Value: "fromcookie"})
+	req.Header.Set("Authorization", "Bearer frombearer")
+	req.Header.Set("X-Session", "fromheader")
+
+	for idx, test := range []struct {
+		extractor pebbleclient.SessionExtractor
+		expected  string
+	}{
+		{pebbleclient.SessionFromQueryParam("session"), "fromquery"},
+		{pebbleclient.SessionFromCookie("checkpoint.session"), "fromcookie"},
+		{pebbleclient.SessionFromBearerToken(), "frombearer"},
+		{pebbleclient.SessionFromHeader("X-Session"), "fromheader"},
+	} {
+		session, ok := test.extractor.ExtractSession(req)
+		assert.True(t, ok, "test %d", idx)
+		assert.Equal(t, test.expected, session, "test %d", idx)
+	}
+
+	empty, err := http.NewRequest("GET", "http://example.com/", nil)
+	require.NoError(t, err)
+	empty.Header.Set("Authorization", "Basic Zm9vOmJhcg==")
+	for idx, extractor := range []pebbleclient.SessionExtractor{
+		pebbleclient.SessionFromQueryParam("session"),
+		pebbleclient.SessionFromCookie("checkpoint.session"),
+		pebbleclient.SessionFromBearerToken(),
+		pebbleclient.SessionFromHeader("X-Session"),
+	} {
+		_, ok := extractor.ExtractSession(empty)
+		assert.False(t, ok, "test %d", idx)
+	}
+}
+
+func TestClient_FromHTTPRequest_sessionExtractors(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://example.com/?session=fromquery", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer frombearer")
+
+	client, err := pebbleclient.NewHTTPClient(pebbleclient.Options{
+		ServiceName: "frobnitz",
+		SessionExtractors: []pebbleclient.SessionExtractor{
+			pebbleclient.SessionFromHeader("X-Session"),
+			pebbleclient.SessionFromBearerToken(),
+			pebbleclient.SessionFromQueryParam("session"),
+		},
+	})
+	require.NoError(t, err)
+
+	client, err = client.FromHTTPRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "frombearer", client.GetOptions().Session)
+}
+
+func TestClient_Get_sessionHeader(t *testing.T) {
+	for idx, test := range []struct {
+		header         string
+		expectedHeader string
+		expectedValue  string
+	}{
+		{"X-Session", "X-Session", "uio3ui3ui3"},
+		{"Authorization", "Authorization", "Bearer uio3ui3ui3"},
+	} {
+		client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+			Session:       "uio3ui3ui3",
+			SessionHeader: test.header,
+		}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, test.expectedValue, req.Header.Get(test.expectedHeader), "test %d", idx)
+			_, err := req.Cookie("checkpoint.session")
+			assert.Equal(t, http.ErrNoCookie, err, "test %d", idx)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		require.NoError(t, err)
+
+		require.NoError(t, client.Get("hello", nil, nil))
+		server.Close()
+	}
+}
+
+func TestClient_Get_sessionCookie(t *testing.T) {
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		Session: "uio3ui3ui3",
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cookie, err := req.Cookie("checkpoint.session")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "uio3ui3ui3", cookie.Value)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("hello", nil, nil))
+}
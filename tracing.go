@@ -0,0 +1,95 @@
+package pebbleclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/context"
+)
+
+const tracerName = "github.com/t11e/go-pebbleclient"
+
+// tracePropagator is always W3C trace context, regardless of the global
+// propagator, which is a no-op unless configured.
+var tracePropagator = propagation.TraceContext{}
+
+func (client *HTTPClient) tracer() trace.Tracer {
+	provider := client.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// startCallSpan starts the span for a logical call, which covers all attempts.
+// The span is named after the path template, as passed to Do before parameters
+// are interpolated, so that UIDs and such don't end up in span names; see
+// normalizePathTemplate for paths that are not templates.
+func (client *HTTPClient) startCallSpan(
+	ctx context.Context,
+	method string,
+	pathTemplate string) (context.Context, trace.Span) {
+	template := normalizePathTemplate(pathTemplate)
+	return client.tracer().Start(ctx, fmt.Sprintf("%s %s", method, template),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("pebble.service", client.ServiceName),
+			attribute.Int("pebble.api_version", client.APIVersion),
+			attribute.String("server.address", client.Host),
+			attribute.String("http.request.method", method),
+			attribute.String("url.template", template),
+		))
+}
+
+// startAttemptSpan starts the span for a single attempt, and injects its trace
+// context into the request headers.
+func (client *HTTPClient) startAttemptSpan(
+	ctx context.Context,
+	req *http.Request,
+	attempt int) (context.Context, trace.Span) {
+	ctx, span := client.tracer().Start(ctx, fmt.Sprintf("%s attempt %d", req.Method, attempt),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("pebble.attempt", attempt),
+		))
+	tracePropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, span
+}
+
+// endSpan records the outcome of a call or attempt, and ends the span.
+func endSpan(span trace.Span, statusCode int, err error) {
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// normalizePathTemplate returns the path template used in span names and
+// metrics. Callers are expected to pass templates such as "/posts/:uid", with
+// the values in the request parameters. A path without any ":param" segments
+// may have values baked into it, such as "/posts/post:acme$1", so only its first
+// segment is kept, followed by "/*" if there are more, to keep the number of
+// distinct templates bounded.
+func normalizePathTemplate(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	segments := strings.Split(path, "/")
+	for _, segment := range segments {
+		if len(segment) > 1 && segment[0] == ':' {
+			return "/" + path
+		}
+	}
+	if len(segments) > 1 {
+		return "/" + segments[0] + "/*"
+	}
+	return "/" + path
+}
@@ -0,0 +1,115 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebbleclient "github.com/t11e/go-pebbleclient"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestClient_Get_tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var traceparents []string
+	count := 0
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		TracerProvider: provider,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparents = append(traceparents, req.Header.Get("traceparent"))
+		count++
+		if count < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("/posts/:uid", &pebbleclient.RequestOptions{
+		Params: pebbleclient.Params{"uid": "post:a.b$1"},
+	}, nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	attempt1, attempt2, call := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "GET /posts/:uid", call.Name())
+	attrs := spanAttributes(call)
+	assert.Equal(t, "frobnitz", attrs["pebble.service"].AsString())
+	assert.Equal(t, int64(1), attrs["pebble.api_version"].AsInt64())
+	assert.Equal(t, hostFromUrl(server.URL), attrs["server.address"].AsString())
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.Equal(t, "/posts/:uid", attrs["url.template"].AsString())
+	assert.Equal(t, int64(204), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Unset, call.Status().Code)
+
+	for i, attempt := range []sdktrace.ReadOnlySpan{attempt1, attempt2} {
+		assert.Equal(t, call.SpanContext().SpanID(), attempt.Parent().SpanID())
+		assert.Equal(t, int64(i+1), spanAttributes(attempt)["pebble.attempt"].AsInt64())
+		require.Len(t, traceparents, 2)
+		assert.Contains(t, traceparents[i], attempt.SpanContext().SpanID().String())
+	}
+	assert.Equal(t, int64(503), spanAttributes(attempt1)["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Error, attempt1.Status().Code)
+	assert.Equal(t, int64(204), spanAttributes(attempt2)["http.response.status_code"].AsInt64())
+}
+
+func TestClient_Get_tracingError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		TracerProvider: provider,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.Error(t, client.Get("hello", nil, nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	call := spans[1]
+	assert.Equal(t, "GET /hello", call.Name())
+	assert.Equal(t, codes.Error, call.Status().Code)
+	assert.Equal(t, int64(404), spanAttributes(call)["http.response.status_code"].AsInt64())
+}
+
+func TestClient_Get_tracingPathWithoutTemplate(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client, server, err := newClientAndServerWithOpts(pebbleclient.Options{
+		TracerProvider: provider,
+	}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	require.NoError(t, err)
+	defer server.Close()
+
+	require.NoError(t, client.Get("/posts/post.listing:acme$1", nil, nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	call := spans[1]
+	assert.Equal(t, "GET /posts/*", call.Name())
+	assert.Equal(t, "/posts/*", spanAttributes(call)["url.template"].AsString())
+}
@@ -3,15 +3,13 @@ package pebbleclient
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ernesto-jimenez/httplogger"
-	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -38,15 +36,67 @@ type Options struct {
 	// Session is an optional Checkpoint session key.
 	Session string
 
+	// SessionHeader is an optional header in which to send the session, instead
+	// of the checkpoint.session cookie. If the header is Authorization, the
+	// session is sent as a bearer token.
+	SessionHeader string
+
+	// SessionExtractors are the extractors used, in order, to find the session
+	// of an incoming request. Defaults to DefaultSessionExtractors.
+	SessionExtractors []SessionExtractor
+
 	// RequestID is an optional request ID that can be passed on to the service.
+	// If not set, the request ID carried by Ctx is used, and failing that, a new
+	// one is generated for each call.
 	RequestID string
 
 	// Logger is an optional interface to permit instrumentation. Ignored if
-	// passing in a custom HTTP client.
+	// passing in a custom HTTP client; see StructuredLogger for an alternative
+	// that is not.
 	Logger httplogger.HTTPLogger
 
 	// Ctx is an optional context.
 	Ctx context.Context
+
+	// RateLimiter is an optional rate limiter. Requests wait for the limiter
+	// before being sent, including retries.
+	RateLimiter *RateLimiter
+
+	// ConcurrencyLimiter is an optional limiter on the number of requests in
+	// flight. Requests wait for a free slot before being sent, including
+	// retries.
+	ConcurrencyLimiter *ConcurrencyLimiter
+
+	// Hedging is an optional hedging policy for GET requests. A hedged request
+	// counts as a single request for the purpose of rate and concurrency
+	// limiting.
+	Hedging *HedgingPolicy
+
+	// Middleware is an optional chain of middleware that each request attempt
+	// is passed through before being sent. The first middleware is the
+	// outermost. Unlike Logger, middleware applies even when passing in a
+	// custom HTTP client.
+	Middleware []Middleware
+
+	// TracerProvider is an optional OpenTelemetry tracer provider. Defaults to
+	// the global tracer provider.
+	TracerProvider trace.TracerProvider
+
+	// Metrics is an optional collector of call metrics.
+	Metrics MetricsCollector
+
+	// StructuredLogger is an optional structured logger, such as a
+	// *slog.Logger. Every attempt is logged, with session keys redacted.
+	StructuredLogger StructuredLogger
+
+	// TrustedProxies is an optional list of networks from which forwarding
+	// headers (Forwarded, X-Forwarded-Host and so on) are trusted when
+	// inferring options from an incoming request. If nil, the immediate peer
+	// is trusted, but only the nearest hop of the forwarding headers is used,
+	// that is, the rightmost X-Forwarded-Host value. If empty but not nil, no
+	// peers are trusted, and forwarding headers are ignored. See
+	// ParseTrustedProxies.
+	TrustedProxies []*net.IPNet
 }
 
 func (o Options) merge(other *Options) Options {
@@ -68,6 +118,12 @@ func (o Options) merge(other *Options) Options {
 	if other.Session != "" {
 		o.Session = other.Session
 	}
+	if other.SessionHeader != "" {
+		o.SessionHeader = other.SessionHeader
+	}
+	if other.SessionExtractors != nil {
+		o.SessionExtractors = other.SessionExtractors
+	}
 	if other.RequestID != "" {
 		o.RequestID = other.RequestID
 	}
@@ -77,6 +133,30 @@ func (o Options) merge(other *Options) Options {
 	if other.Ctx != nil {
 		o.Ctx = other.Ctx
 	}
+	if other.RateLimiter != nil {
+		o.RateLimiter = other.RateLimiter
+	}
+	if other.ConcurrencyLimiter != nil {
+		o.ConcurrencyLimiter = other.ConcurrencyLimiter
+	}
+	if other.Hedging != nil {
+		o.Hedging = other.Hedging
+	}
+	if other.Middleware != nil {
+		o.Middleware = other.Middleware
+	}
+	if other.TracerProvider != nil {
+		o.TracerProvider = other.TracerProvider
+	}
+	if other.Metrics != nil {
+		o.Metrics = other.Metrics
+	}
+	if other.StructuredLogger != nil {
+		o.StructuredLogger = other.StructuredLogger
+	}
+	if other.TrustedProxies != nil {
+		o.TrustedProxies = other.TrustedProxies
+	}
 	return o
 }
 
@@ -172,54 +252,3 @@ type Client interface {
 	Do(path string, opts *RequestOptions, method string, body io.Reader,
 		result interface{}) error
 }
-
-type UID string
-
-var uidRe = regexp.MustCompile(`^([^:]+)\:([^\$]+)\$(.+)$`)
-
-func parseUID(in string) (string, string, int, error) {
-	matches := uidRe.FindStringSubmatch(in)
-	if matches == nil {
-		return "", "", 0, errors.Errorf("invalid uid: %s", in)
-	}
-	class := matches[1]
-	path := matches[2]
-	nuid, err := strconv.Atoi(matches[3])
-	if err != nil {
-		return "", "", 0, errors.Errorf("invalid uid: %s", in)
-	}
-	return class, path, nuid, nil
-}
-
-func (uid UID) Class() (string, error) {
-	class, _, _, err := parseUID(string(uid))
-	return class, err
-}
-
-func (uid UID) Path() (string, error) {
-	_, path, _, err := parseUID(string(uid))
-	return path, err
-}
-
-func (uid UID) NUID() (int, error) {
-	_, _, nuid, err := parseUID(string(uid))
-	return nuid, err
-}
-
-// String implements Stringer.
-func (uid UID) String() string {
-	return string(uid)
-}
-
-// Realm returns the realm part of the path.
-func (uid UID) Realm() (string, error) {
-	path, err := uid.Path()
-	if err != nil {
-		return "", err
-	}
-	i := strings.IndexRune(path, '.')
-	if i == -1 {
-		return path, nil
-	}
-	return path[:i], nil
-}
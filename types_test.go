@@ -1,7 +1,6 @@
 package pebbleclient_test
 
 import (
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,144 +31,3 @@ func Test_Params(t *testing.T) {
 		assert.Len(t, values, 1)
 	}
 }
-
-func TestUID_Class(t *testing.T) {
-	for idx, test := range []struct {
-		in          string
-		expected    string
-		expectedErr string
-	}{
-		{
-			in:          "",
-			expectedErr: "invalid uid: ",
-		},
-		{
-			in:       "a:b$1",
-			expected: "a",
-		},
-		{
-			in:       "post.example:this.is.my.path$1234",
-			expected: "post.example",
-		},
-		{
-			in:          "a:",
-			expectedErr: "invalid uid: a:",
-		},
-		{
-			in:          "a:$",
-			expectedErr: "invalid uid: a:$",
-		},
-		{
-			in:          ":b$1",
-			expectedErr: "invalid uid: :b$1",
-		},
-		{
-			in:          "a:b$c",
-			expectedErr: "invalid uid: a:b$c",
-		},
-	} {
-		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
-			actual, err := pebble.UID(test.in).Class()
-			if test.expectedErr != "" {
-				assert.EqualError(t, err, test.expectedErr)
-			} else {
-				assert.NoError(t, err)
-			}
-			assert.Equal(t, test.expected, actual)
-		})
-	}
-}
-
-func TestUID_Path(t *testing.T) {
-	for idx, test := range []struct {
-		in          string
-		expected    string
-		expectedErr string
-	}{
-		{
-			in:          "",
-			expectedErr: "invalid uid: ",
-		},
-		{
-			in:       "a:b$1",
-			expected: "b",
-		},
-		{
-			in:       "post.example:this.is.my.path$1234",
-			expected: "this.is.my.path",
-		},
-		{
-			in:          ":b",
-			expectedErr: "invalid uid: :b",
-		},
-		{
-			in:          ":b$",
-			expectedErr: "invalid uid: :b$",
-		},
-		{
-			in:          "a:$1",
-			expectedErr: "invalid uid: a:$1",
-		},
-		{
-			in:          "a:b$c",
-			expectedErr: "invalid uid: a:b$c",
-		},
-	} {
-		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
-			actual, err := pebble.UID(test.in).Path()
-			if test.expectedErr != "" {
-				assert.EqualError(t, err, test.expectedErr)
-			} else {
-				assert.NoError(t, err)
-			}
-			assert.Equal(t, test.expected, actual)
-		})
-	}
-}
-
-func TestUID_NUID(t *testing.T) {
-	for idx, test := range []struct {
-		in          string
-		expected    int
-		expectedErr string
-	}{
-		{
-			in:          "",
-			expectedErr: "invalid uid: ",
-		},
-		{
-			in:       "a:b$1",
-			expected: 1,
-		},
-		{
-			in:       "post.example:this.is.my.path$1234",
-			expected: 1234,
-		},
-		{
-			in:          "$b",
-			expectedErr: "invalid uid: $b",
-		},
-		{
-			in:          ":$1",
-			expectedErr: "invalid uid: :$1",
-		},
-		{
-			in:          "a:b$",
-			expectedErr: "invalid uid: a:b$",
-		},
-		{
-			in:          "a:b$c",
-			expectedErr: "invalid uid: a:b$c",
-		},
-	} {
-		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
-			actual, err := pebble.UID(test.in).NUID()
-			if test.expectedErr != "" {
-				assert.EqualError(t, err, test.expectedErr)
-			} else {
-				assert.NoError(t, err)
-			}
-			assert.Equal(t, test.expected, actual)
-		})
-	}
-}
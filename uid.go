@@ -0,0 +1,352 @@
+package pebbleclient
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UID is a Pebble object UID of the form "class:path$nuid", for example
+// "post.listing:acme.blog$42". The class and path are dot-separated lists of
+// labels. UID is a plain string; use Parse or ParseUID to validate it once and
+// access its parts cheaply.
+//
+// The accessors on UID only split the UID into its parts, and accept UIDs that
+// ParseUID would reject, such as those with a zero NUID or unusual characters
+// in their labels.
+type UID string
+
+// Class returns the class part of the UID.
+func (uid UID) Class() (string, error) {
+	parts, err := splitUID(string(uid))
+	return parts.class, err
+}
+
+// Path returns the path part of the UID.
+func (uid UID) Path() (string, error) {
+	parts, err := splitUID(string(uid))
+	return parts.path, err
+}
+
+// NUID returns the numeric ID part of the UID.
+func (uid UID) NUID() (int, error) {
+	parts, err := splitUID(string(uid))
+	return parts.nuid, err
+}
+
+// Parse parses and validates the UID.
+func (uid UID) Parse() (ParsedUID, error) {
+	return ParseUID(string(uid))
+}
+
+// String implements Stringer.
+func (uid UID) String() string {
+	return string(uid)
+}
+
+// Realm returns the realm part of the path.
+func (uid UID) Realm() (string, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return "", err
+	}
+	return parts.Realm(), nil
+}
+
+// BaseClass returns the first label of the class.
+func (uid UID) BaseClass() (string, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return "", err
+	}
+	return parts.BaseClass(), nil
+}
+
+// SubClass returns the class without its base class.
+func (uid UID) SubClass() (string, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return "", err
+	}
+	return parts.SubClass(), nil
+}
+
+// IsA returns true if the class is the given class or one of its subclasses.
+func (uid UID) IsA(class string) (bool, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return false, err
+	}
+	return parts.IsA(class), nil
+}
+
+// PathLabels returns the labels of the path.
+func (uid UID) PathLabels() ([]string, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return nil, err
+	}
+	return parts.PathLabels(), nil
+}
+
+// ParentPaths returns the ancestors of the path, from the realm down.
+func (uid UID) ParentPaths() ([]string, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return nil, err
+	}
+	return parts.ParentPaths(), nil
+}
+
+// HasPathPrefix returns true if the path is the given path or lies below it.
+func (uid UID) HasPathPrefix(prefix string) (bool, error) {
+	parts, err := splitUID(string(uid))
+	if err != nil {
+		return false, err
+	}
+	return parts.HasPathPrefix(prefix), nil
+}
+
+// ParsedUID is a validated UID, split into its class, path and NUID. The zero
+// value is not a valid UID; use ParseUID, MustParseUID or NewUID.
+type ParsedUID struct {
+	class string
+	path  string
+	nuid  int
+}
+
+// ParseUID parses and validates a UID.
+func ParseUID(in string) (ParsedUID, error) {
+	i := strings.IndexByte(in, ':')
+	j := strings.LastIndexByte(in, '$')
+	if i == -1 || j < i {
+		return ParsedUID{}, errors.Errorf("invalid uid: %s", in)
+	}
+	nuid, err := parseNUID(in[j+1:])
+	if err != nil || !isValidLabels(in[:i]) || !isValidLabels(in[i+1:j]) {
+		return ParsedUID{}, errors.Errorf("invalid uid: %s", in)
+	}
+	return ParsedUID{
+		class: in[:i],
+		path:  in[i+1 : j],
+		nuid:  nuid,
+	}, nil
+}
+
+var uidRe = regexp.MustCompile(`^([^:]+)\:([^\$]+)\$(.+)$`)
+
+// splitUID splits a UID into its parts without validating them, as the
+// accessors on UID always have.
+func splitUID(in string) (ParsedUID, error) {
+	matches := uidRe.FindStringSubmatch(in)
+	if matches == nil {
+		return ParsedUID{}, errors.Errorf("invalid uid: %s", in)
+	}
+	nuid, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return ParsedUID{}, errors.Errorf("invalid uid: %s", in)
+	}
+	return ParsedUID{
+		class: matches[1],
+		path:  matches[2],
+		nuid:  nuid,
+	}, nil
+}
+
+// MustParseUID is like ParseUID, but panics if the UID is invalid. It is intended
+// for constants and tests.
+func MustParseUID(in string) ParsedUID {
+	parsed, err := ParseUID(in)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// NewUID constructs a UID from its parts, validating each one.
+func NewUID(class, path string, nuid int) (ParsedUID, error) {
+	if !isValidLabels(class) {
+		return ParsedUID{}, errors.Errorf("invalid uid class: %q", class)
+	}
+	if !isValidLabels(path) {
+		return ParsedUID{}, errors.Errorf("invalid uid path: %q", path)
+	}
+	if nuid <= 0 {
+		return ParsedUID{}, errors.Errorf("invalid uid nuid: %d", nuid)
+	}
+	return ParsedUID{
+		class: class,
+		path:  path,
+		nuid:  nuid,
+	}, nil
+}
+
+// Class returns the class, such as "post.listing".
+func (uid ParsedUID) Class() string {
+	return uid.class
+}
+
+// Path returns the path, such as "acme.blog".
+func (uid ParsedUID) Path() string {
+	return uid.path
+}
+
+// NUID returns the numeric ID.
+func (uid ParsedUID) NUID() int {
+	return uid.nuid
+}
+
+// Realm returns the realm, which is the first label of the path.
+func (uid ParsedUID) Realm() string {
+	if i := strings.IndexByte(uid.path, '.'); i != -1 {
+		return uid.path[:i]
+	}
+	return uid.path
+}
+
+// BaseClass returns the first label of the class, such as "post" for
+// "post.listing".
+func (uid ParsedUID) BaseClass() string {
+	if i := strings.IndexByte(uid.class, '.'); i != -1 {
+		return uid.class[:i]
+	}
+	return uid.class
+}
+
+// SubClass returns the class without its base class, such as "listing" for
+// "post.listing", or an empty string if the class has a single label.
+func (uid ParsedUID) SubClass() string {
+	if i := strings.IndexByte(uid.class, '.'); i != -1 {
+		return uid.class[i+1:]
+	}
+	return ""
+}
+
+// IsA returns true if the class is the given class or one of its subclasses.
+// For example, "post.listing" is a "post", but not a "posting".
+func (uid ParsedUID) IsA(class string) bool {
+	return hasLabelPrefix(uid.class, class)
+}
+
+// PathLabels returns the labels of the path, such as ["acme", "blog"].
+func (uid ParsedUID) PathLabels() []string {
+	return SplitPath(uid.path)
+}
+
+// ParentPaths returns the ancestors of the path, from the realm down. For
+// example, the parent paths of "acme.blog.2017" are "acme" and "acme.blog".
+func (uid ParsedUID) ParentPaths() []string {
+	var parents []string
+	for i := 0; i < len(uid.path); i++ {
+		if uid.path[i] == '.' {
+			parents = append(parents, uid.path[:i])
+		}
+	}
+	return parents
+}
+
+// HasPathPrefix returns true if the path is the given path or lies below it.
+// For example, "acme.blog" has the prefix "acme", but not "ac".
+func (uid ParsedUID) HasPathPrefix(prefix string) bool {
+	return hasLabelPrefix(uid.path, prefix)
+}
+
+// IsZero returns true if this is the zero value.
+func (uid ParsedUID) IsZero() bool {
+	return uid == ParsedUID{}
+}
+
+// UID returns the UID in string form.
+func (uid ParsedUID) UID() UID {
+	return UID(uid.String())
+}
+
+// String implements Stringer.
+func (uid ParsedUID) String() string {
+	if uid.IsZero() {
+		return ""
+	}
+	return uid.class + ":" + uid.path + "$" + strconv.Itoa(uid.nuid)
+}
+
+// SplitPath splits a path or class into its labels.
+func SplitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// JoinPath joins paths or labels with dots, skipping empty ones. For example,
+// JoinPath("acme", "blog.2017") returns "acme.blog.2017".
+func JoinPath(paths ...string) string {
+	nonEmpty := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path != "" {
+			nonEmpty = append(nonEmpty, path)
+		}
+	}
+	return strings.Join(nonEmpty, ".")
+}
+
+// ParentPath returns the path without its last label, or false if the path
+// has a single label.
+func ParentPath(path string) (string, bool) {
+	i := strings.LastIndexByte(path, '.')
+	if i == -1 {
+		return "", false
+	}
+	return path[:i], true
+}
+
+// hasLabelPrefix returns true if s equals prefix, or starts with prefix
+// followed by a dot.
+func hasLabelPrefix(s, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	return len(s) == len(prefix) || s[len(prefix)] == '.'
+}
+
+func parseNUID(s string) (int, error) {
+	if s == "" || s[0] < '1' || s[0] > '9' {
+		return 0, errors.Errorf("invalid nuid: %q", s)
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, errors.Errorf("invalid nuid: %q", s)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// isValidLabels returns true if s is a non-empty, dot-separated list of labels.
+// Labels may contain ASCII letters, digits, underscores and dashes.
+func isValidLabels(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !isValidLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidLabel(label string) bool {
+	if label == "" {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		switch c := label[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,357 @@
+package pebbleclient_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pebble "github.com/t11e/go-pebbleclient"
+)
+
+func TestUID_Class(t *testing.T) {
+	for idx, test := range []struct {
+		in          string
+		expected    string
+		expectedErr string
+	}{
+		{
+			in:          "",
+			expectedErr: "invalid uid: ",
+		},
+		{
+			in:       "a:b$1",
+			expected: "a",
+		},
+		{
+			in:       "post.example:this.is.my.path$1234",
+			expected: "post.example",
+		},
+		{
+			in:          "a:",
+			expectedErr: "invalid uid: a:",
+		},
+		{
+			in:          "a:$",
+			expectedErr: "invalid uid: a:$",
+		},
+		{
+			in:          ":b$1",
+			expectedErr: "invalid uid: :b$1",
+		},
+		{
+			in:          "a:b$c",
+			expectedErr: "invalid uid: a:b$c",
+		},
+	} {
+		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
+			actual, err := pebble.UID(test.in).Class()
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestUID_Path(t *testing.T) {
+	for idx, test := range []struct {
+		in          string
+		expected    string
+		expectedErr string
+	}{
+		{
+			in:          "",
+			expectedErr: "invalid uid: ",
+		},
+		{
+			in:       "a:b$1",
+			expected: "b",
+		},
+		{
+			in:       "post.example:this.is.my.path$1234",
+			expected: "this.is.my.path",
+		},
+		{
+			in:          ":b",
+			expectedErr: "invalid uid: :b",
+		},
+		{
+			in:          ":b$",
+			expectedErr: "invalid uid: :b$",
+		},
+		{
+			in:          "a:$1",
+			expectedErr: "invalid uid: a:$1",
+		},
+		{
+			in:          "a:b$c",
+			expectedErr: "invalid uid: a:b$c",
+		},
+	} {
+		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
+			actual, err := pebble.UID(test.in).Path()
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestUID_NUID(t *testing.T) {
+	for idx, test := range []struct {
+		in          string
+		expected    int
+		expectedErr string
+	}{
+		{
+			in:          "",
+			expectedErr: "invalid uid: ",
+		},
+		{
+			in:       "a:b$1",
+			expected: 1,
+		},
+		{
+			in:       "post.example:this.is.my.path$1234",
+			expected: 1234,
+		},
+		{
+			in:          "$b",
+			expectedErr: "invalid uid: $b",
+		},
+		{
+			in:          ":$1",
+			expectedErr: "invalid uid: :$1",
+		},
+		{
+			in:          "a:b$",
+			expectedErr: "invalid uid: a:b$",
+		},
+		{
+			in:          "a:b$c",
+			expectedErr: "invalid uid: a:b$c",
+		},
+	} {
+		t.Run(fmt.Sprintf("[%d] %s", idx, test.in), func(t *testing.T) {
+			actual, err := pebble.UID(test.in).NUID()
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestUID_Realm(t *testing.T) {
+	realm, err := pebble.UID("post:acme.blog$1").Realm()
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", realm)
+
+	realm, err = pebble.UID("post:acme$1").Realm()
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", realm)
+
+	_, err = pebble.UID("post:acme").Realm()
+	assert.EqualError(t, err, "invalid uid: post:acme")
+}
+
+func TestUID_permissive(t *testing.T) {
+	for _, test := range []struct {
+		in    string
+		class string
+		path  string
+		nuid  int
+	}{
+		{"post:acme$0", "post", "acme", 0},
+		{"post:acme$007", "post", "acme", 7},
+		{"post|event:acme..blog$1", "post|event", "acme..blog", 1},
+		{"po st:acme.*$1", "po st", "acme.*", 1},
+	} {
+		class, err := pebble.UID(test.in).Class()
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.class, class)
+		path, err := pebble.UID(test.in).Path()
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.path, path)
+		nuid, err := pebble.UID(test.in).NUID()
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.nuid, nuid)
+
+		_, err = pebble.ParseUID(test.in)
+		assert.EqualError(t, err, "invalid uid: "+test.in)
+		_, err = pebble.UID(test.in).Parse()
+		assert.Error(t, err, test.in)
+	}
+
+	realm, err := pebble.UID("post:acme_inc*.blog$0").Realm()
+	require.NoError(t, err)
+	assert.Equal(t, "acme_inc*", realm)
+}
+
+func TestUID_hierarchy(t *testing.T) {
+	uid := pebble.UID("post.listing:acme.blog.2017$42")
+
+	baseClass, err := uid.BaseClass()
+	require.NoError(t, err)
+	assert.Equal(t, "post", baseClass)
+	subClass, err := uid.SubClass()
+	require.NoError(t, err)
+	assert.Equal(t, "listing", subClass)
+	isA, err := uid.IsA("post")
+	require.NoError(t, err)
+	assert.True(t, isA)
+	isA, err = uid.IsA("pos")
+	require.NoError(t, err)
+	assert.False(t, isA)
+
+	labels, err := uid.PathLabels()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "blog", "2017"}, labels)
+	parents, err := uid.ParentPaths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "acme.blog"}, parents)
+	hasPrefix, err := uid.HasPathPrefix("acme.blog")
+	require.NoError(t, err)
+	assert.True(t, hasPrefix)
+	hasPrefix, err = uid.HasPathPrefix("ac")
+	require.NoError(t, err)
+	assert.False(t, hasPrefix)
+
+	invalid := pebble.UID("post:acme")
+	_, err = invalid.BaseClass()
+	assert.EqualError(t, err, "invalid uid: post:acme")
+	_, err = invalid.SubClass()
+	assert.Error(t, err)
+	_, err = invalid.IsA("post")
+	assert.Error(t, err)
+	_, err = invalid.PathLabels()
+	assert.Error(t, err)
+	_, err = invalid.ParentPaths()
+	assert.Error(t, err)
+	_, err = invalid.HasPathPrefix("acme")
+	assert.Error(t, err)
+}
+
+func TestParseUID(t *testing.T) {
+	parsed, err := pebble.ParseUID("post.listing:acme.blog$42")
+	require.NoError(t, err)
+	assert.Equal(t, "post.listing", parsed.Class())
+	assert.Equal(t, "acme.blog", parsed.Path())
+	assert.Equal(t, 42, parsed.NUID())
+	assert.Equal(t, "acme", parsed.Realm())
+	assert.Equal(t, pebble.UID("post.listing:acme.blog$42"), parsed.UID())
+	assert.Equal(t, "post.listing:acme.blog$42", parsed.String())
+	assert.False(t, parsed.IsZero())
+
+	parsed, err = pebble.UID("a_b-c:d$1").Parse()
+	require.NoError(t, err)
+	assert.Equal(t, "a_b-c", parsed.Class())
+}
+
+func TestParseUID_invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"post",
+		"post:acme",
+		"post:acme$",
+		"post:acme$0",
+		"post:acme$-1",
+		"post:acme$01",
+		"post:acme$1a",
+		"post:acme$*",
+		":acme$1",
+		"post:$1",
+		"post.:acme$1",
+		"post:acme..blog$1",
+		"post:acme.*$1",
+		"post|event:acme$1",
+		"post:acme$1$2",
+		"po st:acme$1",
+	} {
+		_, err := pebble.ParseUID(in)
+		assert.EqualError(t, err, "invalid uid: "+in)
+	}
+}
+
+func TestMustParseUID(t *testing.T) {
+	assert.Equal(t, 1, pebble.MustParseUID("a:b$1").NUID())
+	assert.Panics(t, func() {
+		pebble.MustParseUID("a:b")
+	})
+}
+
+func TestNewUID(t *testing.T) {
+	uid, err := pebble.NewUID("post", "acme.blog", 7)
+	require.NoError(t, err)
+	assert.Equal(t, pebble.MustParseUID("post:acme.blog$7"), uid)
+
+	_, err = pebble.NewUID("post:x", "acme", 7)
+	assert.EqualError(t, err, `invalid uid class: "post:x"`)
+	_, err = pebble.NewUID("post", "acme$", 7)
+	assert.EqualError(t, err, `invalid uid path: "acme$"`)
+	_, err = pebble.NewUID("post", "acme", 0)
+	assert.EqualError(t, err, "invalid uid nuid: 0")
+}
+
+func TestParsedUID_zero(t *testing.T) {
+	var uid pebble.ParsedUID
+	assert.True(t, uid.IsZero())
+	assert.Equal(t, "", uid.String())
+	assert.Equal(t, pebble.UID(""), uid.UID())
+}
+
+func TestParsedUID_class(t *testing.T) {
+	uid := pebble.MustParseUID("post.listing.open:acme$1")
+	assert.Equal(t, "post", uid.BaseClass())
+	assert.Equal(t, "listing.open", uid.SubClass())
+	assert.True(t, uid.IsA("post"))
+	assert.True(t, uid.IsA("post.listing"))
+	assert.True(t, uid.IsA("post.listing.open"))
+	assert.False(t, uid.IsA("pos"))
+	assert.False(t, uid.IsA("post.list"))
+	assert.False(t, uid.IsA("post.listing.open.x"))
+	assert.False(t, uid.IsA(""))
+
+	uid = pebble.MustParseUID("post:acme$1")
+	assert.Equal(t, "post", uid.BaseClass())
+	assert.Equal(t, "", uid.SubClass())
+}
+
+func TestParsedUID_path(t *testing.T) {
+	uid := pebble.MustParseUID("post:acme.blog.2017$1")
+	assert.Equal(t, []string{"acme", "blog", "2017"}, uid.PathLabels())
+	assert.Equal(t, []string{"acme", "acme.blog"}, uid.ParentPaths())
+	assert.True(t, uid.HasPathPrefix("acme"))
+	assert.True(t, uid.HasPathPrefix("acme.blog"))
+	assert.True(t, uid.HasPathPrefix("acme.blog.2017"))
+	assert.False(t, uid.HasPathPrefix("ac"))
+	assert.False(t, uid.HasPathPrefix("acme.blog.2017.x"))
+	assert.False(t, uid.HasPathPrefix(""))
+
+	uid = pebble.MustParseUID("post:acme$1")
+	assert.Equal(t, []string{"acme"}, uid.PathLabels())
+	assert.Empty(t, uid.ParentPaths())
+}
+
+func TestPathHelpers(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, pebble.SplitPath("a.b"))
+	assert.Nil(t, pebble.SplitPath(""))
+
+	assert.Equal(t, "acme.blog.2017", pebble.JoinPath("acme", "blog.2017"))
+	assert.Equal(t, "acme", pebble.JoinPath("", "acme", ""))
+	assert.Equal(t, "", pebble.JoinPath())
+
+	parent, ok := pebble.ParentPath("acme.blog.2017")
+	assert.True(t, ok)
+	assert.Equal(t, "acme.blog", parent)
+	_, ok = pebble.ParentPath("acme")
+	assert.False(t, ok)
+}
@@ -0,0 +1,191 @@
+package pebbleclient
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// StrictUID is a UID which is validated when decoded from text, JSON or a
+// database, so that malformed UIDs are rejected up front, instead of the error
+// surfacing later when the UID is used. Empty UIDs are accepted. Use it in place
+// of UID in payloads and rows where strictness is wanted; ParsedUID is always
+// strict.
+type StrictUID UID
+
+// UID returns the UID.
+func (uid StrictUID) UID() UID {
+	return UID(uid)
+}
+
+// String returns the UID as a string.
+func (uid StrictUID) String() string {
+	return string(uid)
+}
+
+func decodeParsedUID(s string) (ParsedUID, error) {
+	if s == "" {
+		return ParsedUID{}, nil
+	}
+	return ParseUID(s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (uid UID) MarshalText() ([]byte, error) {
+	return []byte(uid), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The UID is not validated;
+// see StrictUID.
+func (uid *UID) UnmarshalText(text []byte) error {
+	*uid = UID(text)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (uid UID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(uid))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A JSON null leaves the UID
+// unchanged.
+func (uid *UID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Scan implements sql.Scanner. NULL scans as an empty UID.
+func (uid *UID) Scan(src interface{}) error {
+	s, err := scanString(src)
+	if err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer. An empty UID is stored as NULL.
+func (uid UID) Value() (driver.Value, error) {
+	if uid == "" {
+		return nil, nil
+	}
+	return string(uid), nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (uid StrictUID) MarshalText() ([]byte, error) {
+	return []byte(uid), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Malformed UIDs are
+// rejected.
+func (uid *StrictUID) UnmarshalText(text []byte) error {
+	if len(text) > 0 {
+		if _, err := ParseUID(string(text)); err != nil {
+			return err
+		}
+	}
+	*uid = StrictUID(text)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (uid StrictUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(uid))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A JSON null leaves the UID
+// unchanged.
+func (uid *StrictUID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Scan implements sql.Scanner. NULL scans as an empty UID.
+func (uid *StrictUID) Scan(src interface{}) error {
+	s, err := scanString(src)
+	if err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer. An empty UID is stored as NULL.
+func (uid StrictUID) Value() (driver.Value, error) {
+	return UID(uid).Value()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (uid ParsedUID) MarshalText() ([]byte, error) {
+	return []byte(uid.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text decodes as the
+// zero value.
+func (uid *ParsedUID) UnmarshalText(text []byte) error {
+	decoded, err := decodeParsedUID(string(text))
+	if err != nil {
+		return err
+	}
+	*uid = decoded
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (uid ParsedUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uid.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A JSON null leaves the UID
+// unchanged.
+func (uid *ParsedUID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Scan implements sql.Scanner. NULL scans as the zero value.
+func (uid *ParsedUID) Scan(src interface{}) error {
+	s, err := scanString(src)
+	if err != nil {
+		return err
+	}
+	return uid.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer. The zero value is stored as NULL.
+func (uid ParsedUID) Value() (driver.Value, error) {
+	if uid.IsZero() {
+		return nil, nil
+	}
+	return uid.String(), nil
+}
+
+func scanString(src interface{}) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", errors.Errorf("cannot scan %T into uid", src)
+}
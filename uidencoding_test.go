@@ -0,0 +1,147 @@
+package pebbleclient_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pebble "github.com/t11e/go-pebbleclient"
+)
+
+var (
+	_ encoding.TextMarshaler   = pebble.UID("")
+	_ encoding.TextUnmarshaler = (*pebble.UID)(nil)
+	_ json.Marshaler           = pebble.UID("")
+	_ json.Unmarshaler         = (*pebble.UID)(nil)
+	_ sql.Scanner              = (*pebble.UID)(nil)
+	_ driver.Valuer            = pebble.UID("")
+	_ encoding.TextMarshaler   = pebble.StrictUID("")
+	_ encoding.TextUnmarshaler = (*pebble.StrictUID)(nil)
+	_ json.Marshaler           = pebble.StrictUID("")
+	_ json.Unmarshaler         = (*pebble.StrictUID)(nil)
+	_ sql.Scanner              = (*pebble.StrictUID)(nil)
+	_ driver.Valuer            = pebble.StrictUID("")
+	_ json.Marshaler           = pebble.ParsedUID{}
+	_ json.Unmarshaler         = (*pebble.ParsedUID)(nil)
+	_ sql.Scanner              = (*pebble.ParsedUID)(nil)
+	_ driver.Valuer            = pebble.ParsedUID{}
+)
+
+type uidDoc struct {
+	UID    pebble.UID       `json:"uid"`
+	Parsed pebble.ParsedUID `json:"parsed"`
+}
+
+func TestUID_JSON(t *testing.T) {
+	var doc uidDoc
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": "post:acme$1", "parsed": "post:acme.blog$2"}`), &doc))
+	assert.Equal(t, pebble.UID("post:acme$1"), doc.UID)
+	assert.Equal(t, pebble.MustParseUID("post:acme.blog$2"), doc.Parsed)
+
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uid": "post:acme$1", "parsed": "post:acme.blog$2"}`, string(b))
+
+	doc = uidDoc{}
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": null, "parsed": ""}`), &doc))
+	assert.Equal(t, uidDoc{}, doc)
+	b, err = json.Marshal(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uid": "", "parsed": ""}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"uid": 1}`), &doc))
+}
+
+func TestUID_JSON_strict(t *testing.T) {
+	var doc uidDoc
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": "bogus"}`), &doc))
+	assert.Equal(t, pebble.UID("bogus"), doc.UID)
+
+	err := json.Unmarshal([]byte(`{"parsed": "bogus"}`), &doc)
+	assert.EqualError(t, err, "invalid uid: bogus")
+
+	var strict struct {
+		UID pebble.StrictUID `json:"uid"`
+	}
+	err = json.Unmarshal([]byte(`{"uid": "bogus"}`), &strict)
+	assert.EqualError(t, err, "invalid uid: bogus")
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": ""}`), &strict))
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": "a:b$1"}`), &strict))
+	assert.Equal(t, pebble.StrictUID("a:b$1"), strict.UID)
+	assert.Equal(t, pebble.UID("a:b$1"), strict.UID.UID())
+	require.NoError(t, json.Unmarshal([]byte(`{"uid": null}`), &strict))
+	assert.Equal(t, pebble.StrictUID("a:b$1"), strict.UID)
+
+	b, err := json.Marshal(strict)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uid": "a:b$1"}`, string(b))
+}
+
+func TestStrictUID_SQL(t *testing.T) {
+	var uid pebble.StrictUID
+	require.NoError(t, uid.Scan([]byte("a:b$1")))
+	assert.Equal(t, "a:b$1", uid.String())
+	require.NoError(t, uid.Scan(nil))
+	assert.Equal(t, pebble.StrictUID(""), uid)
+	assert.EqualError(t, uid.Scan("a:b"), "invalid uid: a:b")
+
+	value, err := pebble.StrictUID("a:b$1").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "a:b$1", value)
+	value, err = pebble.StrictUID("").Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestUID_Text(t *testing.T) {
+	var uid pebble.UID
+	require.NoError(t, uid.UnmarshalText([]byte("a:b$1")))
+	text, err := uid.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "a:b$1", string(text))
+
+	var parsed pebble.ParsedUID
+	require.NoError(t, parsed.UnmarshalText([]byte("a:b$1")))
+	assert.Equal(t, 1, parsed.NUID())
+	text, err = parsed.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "a:b$1", string(text))
+	assert.Error(t, parsed.UnmarshalText([]byte("a:b")))
+}
+
+func TestUID_SQL(t *testing.T) {
+	var uid pebble.UID
+	require.NoError(t, uid.Scan("a:b$1"))
+	assert.Equal(t, pebble.UID("a:b$1"), uid)
+	require.NoError(t, uid.Scan([]byte("a:b$2")))
+	assert.Equal(t, pebble.UID("a:b$2"), uid)
+	require.NoError(t, uid.Scan(nil))
+	assert.Equal(t, pebble.UID(""), uid)
+	assert.EqualError(t, uid.Scan(42), "cannot scan int into uid")
+
+	value, err := pebble.UID("a:b$1").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "a:b$1", value)
+	value, err = pebble.UID("").Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	var parsed pebble.ParsedUID
+	require.NoError(t, parsed.Scan([]byte("a:b$1")))
+	assert.Equal(t, pebble.MustParseUID("a:b$1"), parsed)
+	require.NoError(t, parsed.Scan(nil))
+	assert.True(t, parsed.IsZero())
+	assert.EqualError(t, parsed.Scan("a:b"), "invalid uid: a:b")
+
+	value, err = pebble.MustParseUID("a:b$1").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "a:b$1", value)
+	value, err = pebble.ParsedUID{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
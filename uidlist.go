@@ -0,0 +1,191 @@
+package pebbleclient
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// UIDList is a list of UIDs. Its string form is the UIDs joined with commas,
+// which is what Grove expects for multiple UIDs, so it can be used directly as
+// a path or query parameter value:
+//
+//	client.Get("/posts/:uids", &pc.RequestOptions{
+//		Params: pc.Params{"uids": uids},
+//	}, &result)
+type UIDList []UID
+
+// ParseUIDList parses a comma-separated list of UIDs, validating each one.
+func ParseUIDList(s string) (UIDList, error) {
+	if s == "" {
+		return UIDList{}, nil
+	}
+	parts := strings.Split(s, ",")
+	list := make(UIDList, len(parts))
+	for i, part := range parts {
+		parsed, err := ParseUID(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list[i] = parsed.UID()
+	}
+	return list, nil
+}
+
+// String implements Stringer.
+func (list UIDList) String() string {
+	strs := make([]string, len(list))
+	for i, uid := range list {
+		strs[i] = string(uid)
+	}
+	return strings.Join(strs, ",")
+}
+
+// Dedup returns a copy of the list with duplicates removed, keeping the first
+// occurrence of each UID.
+func (list UIDList) Dedup() UIDList {
+	seen := make(map[UID]struct{}, len(list))
+	result := make(UIDList, 0, len(list))
+	for _, uid := range list {
+		if _, ok := seen[uid]; !ok {
+			seen[uid] = struct{}{}
+			result = append(result, uid)
+		}
+	}
+	return result
+}
+
+// Sort sorts the list in place by class, then path, then NUID. Sorting is
+// stable. Malformed UIDs are sorted last, by their string form.
+func (list UIDList) Sort() {
+	parsed := make(map[UID]ParsedUID, len(list))
+	for _, uid := range list {
+		if p, err := ParseUID(string(uid)); err == nil {
+			parsed[uid] = p
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, aOK := parsed[list[i]]
+		b, bOK := parsed[list[j]]
+		switch {
+		case aOK && bOK:
+			if a.class != b.class {
+				return a.class < b.class
+			}
+			if a.path != b.path {
+				return a.path < b.path
+			}
+			return a.nuid < b.nuid
+		case aOK != bOK:
+			return aOK
+		default:
+			return list[i] < list[j]
+		}
+	})
+}
+
+// GroupByRealm groups the UIDs by realm, keeping their order. Malformed UIDs
+// are grouped under the empty string.
+func (list UIDList) GroupByRealm() map[string]UIDList {
+	return list.groupBy(ParsedUID.Realm)
+}
+
+// GroupByClass groups the UIDs by class, keeping their order. Malformed UIDs
+// are grouped under the empty string.
+func (list UIDList) GroupByClass() map[string]UIDList {
+	return list.groupBy(ParsedUID.Class)
+}
+
+func (list UIDList) groupBy(key func(ParsedUID) string) map[string]UIDList {
+	groups := map[string]UIDList{}
+	for _, uid := range list {
+		var k string
+		if parsed, err := ParseUID(string(uid)); err == nil {
+			k = key(parsed)
+		}
+		groups[k] = append(groups[k], uid)
+	}
+	return groups
+}
+
+// Chunk splits the list into consecutive chunks whose string form, once
+// URL-encoded, is at most maxLength bytes long. This keeps request URLs within
+// server limits when fetching many UIDs at once. A UID which is longer than
+// maxLength on its own gets a chunk of its own.
+func (list UIDList) Chunk(maxLength int) []UIDList {
+	var chunks []UIDList
+	var chunk UIDList
+	length := 0
+	for _, uid := range list {
+		n := len(url.QueryEscape(string(uid)))
+		if len(chunk) > 0 {
+			// Commas are encoded as %2C
+			n += 3
+		}
+		if len(chunk) > 0 && length+n > maxLength {
+			chunks = append(chunks, chunk)
+			chunk, length = nil, 0
+			n -= 3
+		}
+		chunk = append(chunk, uid)
+		length += n
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// UIDSet is a set of UIDs. Like UIDList, it can be used directly as a path or
+// query parameter value; its UIDs are then sorted.
+type UIDSet map[UID]struct{}
+
+// NewUIDSet constructs a set containing some UIDs.
+func NewUIDSet(uids ...UID) UIDSet {
+	set := make(UIDSet, len(uids))
+	set.Add(uids...)
+	return set
+}
+
+// Add adds UIDs to the set.
+func (set UIDSet) Add(uids ...UID) {
+	for _, uid := range uids {
+		set[uid] = struct{}{}
+	}
+}
+
+// Remove removes UIDs from the set.
+func (set UIDSet) Remove(uids ...UID) {
+	for _, uid := range uids {
+		delete(set, uid)
+	}
+}
+
+// Contains returns true if the set contains a UID.
+func (set UIDSet) Contains(uid UID) bool {
+	_, ok := set[uid]
+	return ok
+}
+
+// Len returns the number of UIDs in the set.
+func (set UIDSet) Len() int {
+	return len(set)
+}
+
+// List returns the UIDs in the set as a sorted list.
+func (set UIDSet) List() UIDList {
+	list := make(UIDList, 0, len(set))
+	for uid := range set {
+		list = append(list, uid)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	list.Sort()
+	return list
+}
+
+// String implements Stringer.
+func (set UIDSet) String() string {
+	return set.List().String()
+}
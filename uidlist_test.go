@@ -0,0 +1,112 @@
+package pebbleclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pebble "github.com/t11e/go-pebbleclient"
+)
+
+func TestParseUIDList(t *testing.T) {
+	list, err := pebble.ParseUIDList("a:b$1, c:d$2")
+	require.NoError(t, err)
+	assert.Equal(t, pebble.UIDList{"a:b$1", "c:d$2"}, list)
+	assert.Equal(t, "a:b$1,c:d$2", list.String())
+
+	list, err = pebble.ParseUIDList("")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = pebble.ParseUIDList("a:b$1,c")
+	assert.EqualError(t, err, "invalid uid: c")
+}
+
+func TestUIDList_Dedup(t *testing.T) {
+	list := pebble.UIDList{"c:d$2", "a:b$1", "c:d$2", "a:b$1", "e:f$3"}
+	assert.Equal(t, pebble.UIDList{"c:d$2", "a:b$1", "e:f$3"}, list.Dedup())
+	assert.Len(t, list, 5)
+}
+
+func TestUIDList_Sort(t *testing.T) {
+	list := pebble.UIDList{
+		"bogus",
+		"post:acme.blog$10",
+		"post:acme.blog$9",
+		"event:acme$5",
+		"post:acme$20",
+		"also bogus",
+		"post.listing:acme$1",
+	}
+	list.Sort()
+	assert.Equal(t, pebble.UIDList{
+		"event:acme$5",
+		"post:acme$20",
+		"post:acme.blog$9",
+		"post:acme.blog$10",
+		"post.listing:acme$1",
+		"also bogus",
+		"bogus",
+	}, list)
+}
+
+func TestUIDList_Group(t *testing.T) {
+	list := pebble.UIDList{"post:acme.blog$1", "event:other$2", "post:acme$3", "bogus"}
+	assert.Equal(t, map[string]pebble.UIDList{
+		"acme":  {"post:acme.blog$1", "post:acme$3"},
+		"other": {"event:other$2"},
+		"":      {"bogus"},
+	}, list.GroupByRealm())
+	assert.Equal(t, map[string]pebble.UIDList{
+		"post":  {"post:acme.blog$1", "post:acme$3"},
+		"event": {"event:other$2"},
+		"":      {"bogus"},
+	}, list.GroupByClass())
+}
+
+func TestUIDList_Chunk(t *testing.T) {
+	// Each of these is 9 bytes when encoded ("a%3Ab%241"), plus 3 for commas
+	list := pebble.UIDList{"a:b$1", "a:b$2", "a:b$3", "a:b$4", "a:b$5"}
+	assert.Equal(t, []pebble.UIDList{
+		{"a:b$1", "a:b$2"},
+		{"a:b$3", "a:b$4"},
+		{"a:b$5"},
+	}, list.Chunk(21))
+	assert.Equal(t, []pebble.UIDList{
+		{"a:b$1"}, {"a:b$2"}, {"a:b$3"}, {"a:b$4"}, {"a:b$5"},
+	}, list.Chunk(5))
+	assert.Equal(t, []pebble.UIDList{list}, list.Chunk(1000))
+	assert.Empty(t, pebble.UIDList{}.Chunk(10))
+}
+
+func TestUIDSet(t *testing.T) {
+	set := pebble.NewUIDSet("post:acme$2", "event:acme$1", "post:acme$2")
+	assert.Equal(t, 2, set.Len())
+	assert.True(t, set.Contains("post:acme$2"))
+	assert.False(t, set.Contains("post:acme$3"))
+
+	set.Add("post:acme$10")
+	set.Remove("event:acme$1")
+	assert.Equal(t, pebble.UIDList{"post:acme$2", "post:acme$10"}, set.List())
+	assert.Equal(t, "post:acme$2,post:acme$10", set.String())
+}
+
+func TestUIDList_asParam(t *testing.T) {
+	client, server, err := newClientAndServer(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/frobnitz/v1/posts/post:acme$1,post:acme$2", req.URL.Path)
+		assert.Equal(t, "event:acme$3", req.URL.Query().Get("also"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	err = client.Get("/posts/:uids", &pebble.RequestOptions{
+		Params: pebble.Params{
+			"uids": pebble.UIDList{"post:acme$1", "post:acme$2"},
+			"also": pebble.NewUIDSet("event:acme$3"),
+		},
+	}, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,225 @@
+package pebbleclient
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UIDQuery is a UID pattern, as accepted by Grove, for example
+// "post.listing|post.event:acme.blog|news.*$*". Its parts are:
+//
+// - The class: "*" for any class, or alternatives separated by "|". Each
+// alternative is a class, optionally ending in ".*" to also match subclasses.
+//
+// - The path: dot-separated labels, each of which may have alternatives
+// separated by "|". The last label may be "*" to match zero or more further
+// labels, so that "acme.*" matches "acme" and "acme.blog.2017".
+//
+// - The NUID: "*" for any NUID, or alternatives separated by "|". It may be
+// omitted, along with the "$", in which case it matches any NUID.
+type UIDQuery struct {
+	classes   [][]string
+	path      [][]string
+	pathTail  bool
+	nuids     []int
+	anyClass  bool
+	anyNUID   bool
+	formatted string
+}
+
+// ParseUIDQuery parses a UID query.
+func ParseUIDQuery(in string) (UIDQuery, error) {
+	invalid := errors.Errorf("invalid uid query: %s", in)
+
+	i := strings.IndexByte(in, ':')
+	if i == -1 {
+		return UIDQuery{}, invalid
+	}
+	classStr, pathStr, nuidStr := in[:i], in[i+1:], "*"
+	if j := strings.LastIndexByte(pathStr, '$'); j != -1 {
+		pathStr, nuidStr = pathStr[:j], pathStr[j+1:]
+	}
+
+	var query UIDQuery
+	if classStr == "*" {
+		query.anyClass = true
+	} else {
+		for _, alt := range strings.Split(classStr, "|") {
+			labels := strings.Split(alt, ".")
+			for k, label := range labels {
+				if !isValidLabel(label) && !(label == "*" && k > 0 && k == len(labels)-1) {
+					return UIDQuery{}, invalid
+				}
+			}
+			query.classes = append(query.classes, labels)
+		}
+	}
+
+	labels := strings.Split(pathStr, ".")
+	if labels[len(labels)-1] == "*" {
+		query.pathTail = true
+		labels = labels[:len(labels)-1]
+	}
+	for _, label := range labels {
+		alts := strings.Split(label, "|")
+		for _, alt := range alts {
+			if !isValidLabel(alt) {
+				return UIDQuery{}, invalid
+			}
+		}
+		query.path = append(query.path, alts)
+	}
+
+	if nuidStr == "*" {
+		query.anyNUID = true
+	} else {
+		for _, alt := range strings.Split(nuidStr, "|") {
+			nuid, err := parseNUID(alt)
+			if err != nil {
+				return UIDQuery{}, invalid
+			}
+			query.nuids = append(query.nuids, nuid)
+		}
+	}
+
+	query.formatted = query.format()
+	return query, nil
+}
+
+// MustParseUIDQuery is like ParseUIDQuery, but panics if the query is invalid.
+func MustParseUIDQuery(in string) UIDQuery {
+	query, err := ParseUIDQuery(in)
+	if err != nil {
+		panic(err)
+	}
+	return query
+}
+
+// Matches returns true if a UID matches the query. Invalid UIDs never match.
+func (query UIDQuery) Matches(uid UID) bool {
+	parsed, err := ParseUID(string(uid))
+	if err != nil {
+		return false
+	}
+	return query.MatchesParsed(parsed)
+}
+
+// MatchesParsed returns true if a parsed UID matches the query.
+func (query UIDQuery) MatchesParsed(uid ParsedUID) bool {
+	if uid.IsZero() || query.formatted == "" {
+		return false
+	}
+	return query.matchClass(uid.class) && query.matchPath(uid.path) &&
+		query.matchNUID(uid.nuid)
+}
+
+func (query UIDQuery) matchClass(class string) bool {
+	if query.anyClass {
+		return true
+	}
+	labels := strings.Split(class, ".")
+	for _, alt := range query.classes {
+		if matchLabels(alt, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLabels matches a class pattern, which may end in "*", against labels.
+func matchLabels(pattern, labels []string) bool {
+	n := len(pattern)
+	tail := pattern[n-1] == "*"
+	if tail {
+		n--
+	}
+	if len(labels) < n || (!tail && len(labels) != n) {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if pattern[i] != labels[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (query UIDQuery) matchPath(path string) bool {
+	labels := strings.Split(path, ".")
+	if len(labels) < len(query.path) || (!query.pathTail && len(labels) != len(query.path)) {
+		return false
+	}
+	for i, alts := range query.path {
+		if !containsString(alts, labels[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (query UIDQuery) matchNUID(nuid int) bool {
+	if query.anyNUID {
+		return true
+	}
+	for _, n := range query.nuids {
+		if n == nuid {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the query in canonical form, which always includes the NUID.
+func (query UIDQuery) String() string {
+	return query.formatted
+}
+
+func (query UIDQuery) format() string {
+	var b strings.Builder
+	if query.anyClass {
+		b.WriteString("*")
+	} else {
+		for i, alt := range query.classes {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteString(strings.Join(alt, "."))
+		}
+	}
+	b.WriteByte(':')
+	for i, alts := range query.path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strings.Join(alts, "|"))
+	}
+	if query.pathTail {
+		if len(query.path) > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteByte('*')
+	}
+	b.WriteByte('$')
+	if query.anyNUID {
+		b.WriteByte('*')
+	} else {
+		for i, nuid := range query.nuids {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteString(strconv.Itoa(nuid))
+		}
+	}
+	return b.String()
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
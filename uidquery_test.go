@@ -0,0 +1,88 @@
+package pebbleclient_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pebble "github.com/t11e/go-pebbleclient"
+)
+
+func TestParseUIDQuery_String(t *testing.T) {
+	for in, expected := range map[string]string{
+		"post:acme.blog$1":                  "post:acme.blog$1",
+		"post.*:acme.blog.*$*":              "post.*:acme.blog.*$*",
+		"post.listing|post.event:acme.*":    "post.listing|post.event:acme.*$*",
+		"*:*$*":                             "*:*$*",
+		"*:acme.blog|news$1|2|3":            "*:acme.blog|news$1|2|3",
+		"post:acme_inc.my-blog$42":          "post:acme_inc.my-blog$42",
+		"post.listing.*|post.event:acme$17": "post.listing.*|post.event:acme$17",
+	} {
+		query, err := pebble.ParseUIDQuery(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, expected, query.String())
+	}
+}
+
+func TestParseUIDQuery_invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"post",
+		":acme$1",
+		"post:$1",
+		"post:acme$",
+		"post:acme$0",
+		"post:acme$x",
+		"post:acme$1|",
+		"*.post:acme",
+		"post|*:acme",
+		"post.*.x:acme",
+		"post:acme..blog",
+		"post:*.acme",
+		"post:acme|:x",
+		"po st:acme",
+	} {
+		_, err := pebble.ParseUIDQuery(in)
+		assert.EqualError(t, err, "invalid uid query: "+in)
+	}
+	assert.Panics(t, func() {
+		pebble.MustParseUIDQuery("post")
+	})
+}
+
+func TestUIDQuery_Matches(t *testing.T) {
+	for _, test := range []struct {
+		query   string
+		uid     pebble.UID
+		matches bool
+	}{
+		{"post:acme.blog$1", "post:acme.blog$1", true},
+		{"post:acme.blog$1", "post:acme.blog$2", false},
+		{"post:acme.blog$1", "post.listing:acme.blog$1", false},
+		{"post:acme.blog$1", "post:acme.blog.x$1", false},
+		{"post:acme.blog$1", "post:acme$1", false},
+		{"post.*:acme$*", "post:acme$1", true},
+		{"post.*:acme$*", "post.listing:acme$1", true},
+		{"post.*:acme$*", "post.listing.open:acme$1", true},
+		{"post.*:acme$*", "postal:acme$1", false},
+		{"post.listing|post.event:acme", "post.event:acme$3", true},
+		{"post.listing|post.event:acme", "post:acme$3", false},
+		{"*:acme.*", "anything:acme$1", true},
+		{"*:acme.*", "anything:acme.blog.2017$1", true},
+		{"*:acme.*", "anything:acme_inc$1", false},
+		{"*:*$*", "a:b$1", true},
+		{"*:acme.blog|news.*$1|2", "a:acme.news.x$2", true},
+		{"*:acme.blog|news.*$1|2", "a:acme.news$1", true},
+		{"*:acme.blog|news.*$1|2", "a:acme.sports$1", false},
+		{"*:acme.blog|news.*$1|2", "a:acme.blog$3", false},
+		{"*:*", "not a uid", false},
+	} {
+		query := pebble.MustParseUIDQuery(test.query)
+		assert.Equal(t, test.matches, query.Matches(test.uid), "%s ~ %s", test.query, test.uid)
+	}
+
+	var zero pebble.UIDQuery
+	assert.False(t, zero.Matches("a:b$1"))
+	assert.True(t, pebble.MustParseUIDQuery("a:b").MatchesParsed(pebble.MustParseUID("a:b$1")))
+}
@@ -13,17 +13,17 @@ import (
 	"github.com/pkg/errors"
 )
 
-func hostFromRequest(req *http.Request) (string, bool) {
-	var host string
-	if hosts, ok := req.Header["X-Forwarded-Host"]; ok && len(hosts) > 0 {
-		host = hosts[len(hosts)-1]
-	} else {
-		host = req.Host
-	}
-	if host != "" {
-		return host, true
+// upstreamKey identifies a service on a host.
+type upstreamKey struct {
+	host        string
+	serviceName string
+}
+
+func newUpstreamKey(host, serviceName string) upstreamKey {
+	return upstreamKey{
+		host:        strings.ToLower(host),
+		serviceName: serviceName,
 	}
-	return "", false
 }
 
 type MissingParameter struct {